@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+)
+
+const apikeyUsage = `Manage API keys.
+
+Usage:
+  leaderboard apikey create -name <name> -scopes <read,submit-scores,admin>
+  leaderboard apikey list
+  leaderboard apikey revoke -id <id>
+`
+
+// runAPIKey implements the apikey command
+func runAPIKey(args []string) {
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, apikeyUsage)
+		os.Exit(2)
+	}
+
+	a, err := app.Open(context.Background(), app.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+
+	authService := service.NewAuthService(a.APIKeyRepo, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("create", flag.ExitOnError)
+		name := fs.String("name", "", "descriptive name for the key")
+		scopes := fs.String("scopes", "read", "comma-separated scopes: read, submit-scores, admin")
+		fs.Parse(args[1:])
+
+		var key *models.CreatedAPIKey
+		err := auditCLI(ctx, a, audit.ActionAPIKeyCreate, "", func(ctx context.Context) (err error) {
+			key, err = authService.CreateAPIKey(ctx, *name, *scopes)
+			return err
+		})
+		if err != nil {
+			log.Fatal("Failed to create API key:", err)
+		}
+		fmt.Printf("Created API key %d (%s) with scopes %s\n", key.ID, key.Name, key.Scopes)
+		fmt.Printf("\n  %s\n\nStore it now; it cannot be shown again.\n", key.Key)
+
+	case "list":
+		keys, err := authService.ListAPIKeys(ctx)
+		if err != nil {
+			log.Fatal("Failed to list API keys:", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tLAST USED\tSTATUS")
+		for _, k := range keys {
+			lastUsed, status := "never", "active"
+			if k.LastUsedAt != nil {
+				lastUsed = k.LastUsedAt.Format(time.RFC3339)
+			}
+			if k.RevokedAt != nil {
+				status = "revoked"
+			}
+			fmt.Fprintf(w, "%d\t%s\tlb_%s\t%s\t%s\t%s\t%s\n",
+				k.ID, k.Name, k.Prefix, k.Scopes, k.CreatedAt.Format(time.RFC3339), lastUsed, status)
+		}
+		w.Flush()
+
+	case "revoke":
+		fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+		id := fs.Int("id", 0, "id of the key to revoke")
+		fs.Parse(args[1:])
+
+		err := auditCLI(ctx, a, audit.ActionAPIKeyRevoke, strconv.Itoa(*id), func(ctx context.Context) error {
+			return authService.RevokeAPIKey(ctx, *id)
+		})
+		if err != nil {
+			log.Fatal("Failed to revoke API key:", err)
+		}
+		fmt.Printf("Revoked API key %d\n", *id)
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown apikey command %q\n\n%s", args[0], apikeyUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os/user"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/service"
+)
+
+// auditCLI runs fn as action and records it in the audit log, like the admin
+// API does for its routes. The actor is the operating system user running
+// the command.
+func auditCLI(ctx context.Context, a *app.App, action, target string, fn func(ctx context.Context) error) error {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	ctx, record := audit.Begin(audit.WithActor(ctx, audit.Actor{ID: "cli:" + name, Name: name}))
+	if target != "" {
+		audit.SetTarget(ctx, audit.TargetType(action), target)
+	}
+
+	err := fn(ctx)
+	if recordErr := service.NewAuditService(a.AuditRepo).Record(context.WithoutCancel(ctx), record.Event(ctx, action, err)); recordErr != nil {
+		log.Printf("Failed to record audit event %s: %v", action, recordErr)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/service"
+)
+
+const exportUsage = `Write the whole leaderboard with tie-aware ranks.
+
+Usage:
+  leaderboard export [-format csv|jsonl] [-out FILE]
+
+Flags:
+`
+
+// runExport implements the export command
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", service.FormatCSV, "output format: csv or jsonl")
+	out := fs.String("out", "-", "output file, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, exportUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	a, err := app.Open(ctx, app.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal("Failed to create output file: ", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	count, err := service.NewExportService(a.UserRepo).Export(ctx, w, *format)
+	if err != nil {
+		log.Fatal("Export failed: ", err)
+	}
+	log.Printf("Exported %d users", count)
+}
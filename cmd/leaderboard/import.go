@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+)
+
+const importUsage = `Upsert users and ratings from CSV or JSON lines, then rebuild Redis.
+
+CSV files need a header with username and rating columns; other columns are
+ignored, so an export can be imported as is. JSON lines are objects with
+username and rating. Invalid rows are reported and skipped.
+
+Usage:
+  leaderboard import [-format csv|jsonl] [-in FILE] [-show N]
+
+Flags:
+`
+
+// runImport implements the import command. It exits with status 1 if any row
+// failed or Redis could not be rebuilt.
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", service.FormatCSV, "input format: csv or jsonl")
+	in := fs.String("in", "-", "input file, - for stdin")
+	show := fs.Int("show", 20, "row errors to print")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, importUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatal("Failed to open input file: ", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	a, err := app.Open(ctx, app.Options{Redis: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+	if a.RedisRepo != nil {
+		if err := a.RedisRepo.Connect(ctx); err != nil {
+			log.Printf("Warning: Redis unreachable: %v", err)
+		}
+	}
+
+	// No local page cache to invalidate; servers' cached pages expire on their own
+	broadcaster := cache.NewBroadcaster(cache.NewPageCache(0, 0), a.RedisRepo)
+	syncService := service.NewSyncService(a.UserRepo, a.RedisRepo, broadcaster)
+	importService := service.NewImportService(a.UserRepo, syncService)
+	var report *models.ImportReport
+	err = auditCLI(ctx, a, audit.ActionUsersImport, "", func(ctx context.Context) (err error) {
+		report, err = importService.Import(ctx, r, *format)
+		return err
+	})
+	if err != nil {
+		log.Fatal("Import failed: ", err)
+	}
+
+	fmt.Printf("Rows:      %d\n", report.Rows)
+	fmt.Printf("Inserted:  %d\n", report.Inserted)
+	fmt.Printf("Updated:   %d\n", report.Updated)
+	fmt.Printf("Unchanged: %d\n", report.Unchanged)
+	fmt.Printf("Failed:    %d\n", report.Failed)
+	for _, e := range limit(report.Errors, *show) {
+		fmt.Printf("  line %d: %s (%s)\n", e.Line, e.Error, e.Username)
+	}
+
+	if !report.RedisSynced {
+		fmt.Printf("Redis was not rebuilt: %s\nRun \"leaderboard sync-redis\" once it is reachable.\n", report.RedisError)
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/scheduler"
+	"matiks/leaderboard/internal/service"
+)
+
+// jobServices are the services the scheduled jobs call into
+type jobServices struct {
+	redisRepo    *repository.RedisRepository
+	update       *service.UpdateService
+	sync         *service.SyncService
+	snapshot     *service.SnapshotService
+	peak         *service.PeakService
+	decay        *service.DecayService
+	webhook      *service.WebhookService
+	achievements *service.AchievementService
+	decayMode    string
+}
+
+// registerJobs adds every enabled job to the scheduler. Rating decay is only
+// registered while DECAY_MODE is on, and Redis reconciliation only with Redis.
+func registerJobs(ctx context.Context, sched *scheduler.Scheduler, svc jobServices) error {
+	jobsConfig := config.LoadJobs()
+
+	jobs := []scheduler.Job{
+		{
+			// Off until an admin resumes it; it rewrites real ratings
+			Name:    config.JobSimulateUpdates,
+			Timeout: time.Minute,
+			Paused:  true,
+			Run: func(ctx context.Context) (string, error) {
+				if err := svc.update.SimulateRandomUpdates(ctx, 10); err != nil {
+					return "", err
+				}
+				return "queued 10 random updates", nil
+			},
+		},
+		{
+			Name:    config.JobSnapshotLeaderboard,
+			Timeout: 10 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				snapshot, removed, err := svc.snapshot.Take(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("snapshot %d of %d users, %d expired snapshots removed", snapshot.ID, snapshot.TotalUsers, removed), nil
+			},
+		},
+		{
+			// Records peak ranks gained when users above drop or leave the board
+			Name:    config.JobPeakRankSweep,
+			Timeout: 5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				updated, err := svc.peak.Sweep(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d new peak ranks", updated), nil
+			},
+		},
+		{
+			Name:    config.JobPruneWebhooks,
+			Timeout: 10 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				removed, err := svc.webhook.Prune(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d old deliveries removed", removed), nil
+			},
+		},
+		{
+			// Backfills rating achievements and tracks top percentages,
+			// which change as other players move
+			Name:    config.JobAchievementSweep,
+			Timeout: 10 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				awarded, err := svc.achievements.Sweep(ctx)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("%d achievements awarded", awarded), nil
+			},
+		},
+	}
+	if svc.redisRepo != nil {
+		jobs = append(jobs, scheduler.Job{
+			// Catches drift the write path missed, e.g. a mirror write that
+			// failed without tripping the circuit
+			Name:    config.JobReconcileRedis,
+			Timeout: 5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				if !svc.redisRepo.Available() {
+					return "skipped: Redis unavailable", nil
+				}
+				report, resynced, err := svc.sync.Reconcile(ctx)
+				if err != nil {
+					return "", err
+				}
+				if !resynced {
+					return fmt.Sprintf("in sync (%d users)", report.DatabaseUsers), nil
+				}
+				return fmt.Sprintf("re-synced after drift: %d missing, %d extra, %d mismatched, %d stale profiles",
+					len(report.MissingInRedis), len(report.ExtraInRedis), len(report.Mismatched), len(report.StaleProfiles)), nil
+			},
+		})
+	}
+	if svc.decayMode != models.DecayOff {
+		jobs = append(jobs, scheduler.Job{
+			Name:    config.JobRatingDecay,
+			Timeout: 30 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				report, err := svc.decay.Run(ctx, false)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("decay run %d: examined %d, decayed %d, hidden %d",
+					report.ID, report.Examined, report.Decayed, report.Hidden), nil
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		if !jobsConfig.Enabled(job.Name) {
+			log.Printf("Job %s disabled", job.Name)
+			continue
+		}
+		job.Schedule = jobsConfig.Schedule(job.Name)
+		if err := sched.Register(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `leaderboard runs and administers the leaderboard service.
+
+Usage:
+  leaderboard <command> [arguments]
+
+Commands:
+  serve        run the HTTP API
+  migrate      apply, roll back or list database migrations
+  seed         fill the database with generated users
+  sync-redis   rebuild the Redis leaderboard from the database
+  verify       compare the Redis leaderboard with the database
+  import       upsert users and ratings from CSV or JSON lines
+  export       write the leaderboard with ranks as CSV or JSON lines
+  apikey       create, list and revoke API keys
+
+Run "leaderboard <command> -h" for a command's flags. Every command reads the
+same environment variables (DATABASE_URL, REDIS_URL, ...) as the server.
+`
+
+var commands = map[string]func(args []string){
+	"serve":      runServe,
+	"migrate":    runMigrate,
+	"seed":       runSeed,
+	"sync-redis": runSyncRedis,
+	"verify":     runVerify,
+	"import":     runImport,
+	"export":     runExport,
+	"apikey":     runAPIKey,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	run(os.Args[2:])
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/migrate"
+)
+
+const migrateUsage = `Manage the database schema.
+
+Usage:
+  leaderboard migrate up                 apply all pending migrations
+  leaderboard migrate down [-steps N]    roll back the last N migrations (default 1)
+  leaderboard migrate status             list migrations and when they were applied
+`
+
+// runMigrate implements the migrate command
+func runMigrate(args []string) {
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	a, err := app.Open(context.Background(), app.Options{SkipSchemaCheck: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		log.Fatal("Failed to get database handle:", err)
+	}
+	migrator, err := migrate.New(sqlDB)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	// Generous: a migration may rewrite a large table
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal("Migration failed:", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args[1:])
+		if *steps < 1 {
+			log.Fatal("-steps must be at least 1")
+		}
+
+		rolledBack, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			log.Fatal("Rollback failed:", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+		for _, m := range rolledBack {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal("Failed to read migration status:", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			if s.Missing {
+				applied += " (no migration file in this build)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/seed"
+
+	"gorm.io/gorm"
+)
+
+const seedUsage = `Fill the database with generated users, matches and rating history.
+The same flags always generate the same data; pass -end to keep it identical
+across days.
+
+Usage:
+  leaderboard seed [-count N] [-distribution %s] [-seed N] [-truncate]
+
+Flags:
+`
+
+// runSeed implements the seed command
+func runSeed(args []string) {
+	defaults := seed.DefaultOptions()
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	count := fs.Int("count", defaults.Count, "number of users to generate")
+	distribution := fs.String("distribution", defaults.Distribution, "rating distribution: "+strings.Join(seed.Distributions, ", "))
+	randomSeed := fs.Int64("seed", defaults.Seed, "random seed")
+	truncate := fs.Bool("truncate", false, "delete existing users first instead of refusing to seed a non-empty database")
+	tiers := fs.String("tiers", "", "tiers for the tiered distribution as min-max:weight,... (default: built-in tiers)")
+	mean := fs.Float64("mean", defaults.Mean, "mean rating for the normal distribution")
+	stddev := fs.Float64("stddev", defaults.StdDev, "standard deviation for the normal distribution")
+	alpha := fs.Float64("alpha", defaults.Alpha, "exponent for the powerlaw distribution")
+	tieDensity := fs.Float64("tie-density", defaults.TieDensity, "share of users snapped to a multiple of 500 (0-1)")
+	matchesPerUser := fs.Int("matches-per-user", defaults.MatchesPerUser, "average matches per user; 0 skips history")
+	historyDays := fs.Int("history-days", defaults.HistoryDays, "days of match history")
+	end := fs.String("end", defaults.EndTime.Format(time.DateOnly), "date (YYYY-MM-DD, UTC) the history ends at")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, seedUsage, strings.Join(seed.Distributions, "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	endTime, err := time.Parse(time.DateOnly, *end)
+	if err != nil {
+		log.Fatalf("Invalid -end: %v", err)
+	}
+	opts := seed.Options{
+		Count:          *count,
+		Distribution:   *distribution,
+		Tiers:          defaults.Tiers,
+		Mean:           *mean,
+		StdDev:         *stddev,
+		Alpha:          *alpha,
+		TieDensity:     *tieDensity,
+		MatchesPerUser: *matchesPerUser,
+		HistoryDays:    *historyDays,
+		EndTime:        endTime,
+		Seed:           *randomSeed,
+		Truncate:       *truncate,
+	}
+	if *tiers != "" {
+		if opts.Tiers, err = seed.ParseTiers(*tiers); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	a, err := app.Open(context.Background(), app.Options{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if _, err := seed.Run(ctx, a.DB, opts); err != nil {
+		log.Fatal("Seeding failed: ", err)
+	}
+
+	showStatistics(a.DB)
+}
+
+// showStatistics displays some statistics about the seeded data
+func showStatistics(db *gorm.DB) {
+	log.Println("\nDatabase Statistics:")
+
+	// Total users
+	var totalCount int64
+	db.Model(&models.User{}).Count(&totalCount)
+	log.Printf("  Total users: %d", totalCount)
+
+	var matchCount, historyCount int64
+	db.Model(&models.Match{}).Count(&matchCount)
+	db.Model(&models.RatingHistory{}).Count(&historyCount)
+	log.Printf("  Matches: %d (%d rating history rows)", matchCount, historyCount)
+
+	// Rating statistics
+	var minRating, maxRating, avgRating int
+	db.Model(&models.User{}).Select("MIN(rating)").Scan(&minRating)
+	db.Model(&models.User{}).Select("MAX(rating)").Scan(&maxRating)
+	db.Model(&models.User{}).Select("AVG(rating)").Scan(&avgRating)
+	log.Printf("  Rating range: %d - %d (avg: %d)", minRating, maxRating, avgRating)
+
+	// Count users by rating tier
+	var topTier, highTier, midTier, lowTier int64
+	db.Model(&models.User{}).Where("rating >= ?", 4500).Count(&topTier)
+	db.Model(&models.User{}).Where("rating >= ? AND rating < ?", 3500, 4500).Count(&highTier)
+	db.Model(&models.User{}).Where("rating >= ? AND rating < ?", 2500, 3500).Count(&midTier)
+	db.Model(&models.User{}).Where("rating < ?", 2500).Count(&lowTier)
+
+	log.Println("\n  Rating Distribution:")
+	log.Printf("    Top tier (4500-5000): %d users", topTier)
+	log.Printf("    High tier (3500-4499): %d users", highTier)
+	log.Printf("    Mid tier (2500-3499): %d users", midTier)
+	log.Printf("    Low tier (100-2499): %d users", lowTier)
+
+	// Find some example ties
+	log.Println("\n  Example Ties (users with same rating):")
+	var tieExamples []models.User
+	db.Where("rating IN (SELECT rating FROM users GROUP BY rating HAVING COUNT(*) > 1)").
+		Order("rating DESC").
+		Limit(10).
+		Find(&tieExamples)
+
+	if len(tieExamples) > 0 {
+		ratingGroups := make(map[int][]string)
+		for _, user := range tieExamples {
+			ratingGroups[user.Rating] = append(ratingGroups[user.Rating], user.Username)
+		}
+
+		count := 0
+		for rating, usernames := range ratingGroups {
+			if count >= 5 {
+				break
+			}
+			if len(usernames) > 1 {
+				log.Printf("    Rating %d: %d users (e.g., %s, %s)", rating, len(usernames), usernames[0], usernames[1])
+				count++
+			}
+		}
+	}
+
+	log.Println("\nSeeding completed successfully!")
+	log.Println("Redis is not updated by seeding; a running server re-syncs on restart, or run: go run ./cmd/leaderboard sync-redis")
+	log.Println("You can now start the server with: go run ./cmd/leaderboard serve")
+}
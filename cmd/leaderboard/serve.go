@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/auth"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/controllers"
+	"matiks/leaderboard/internal/handlers"
+	"matiks/leaderboard/internal/middleware"
+	"matiks/leaderboard/internal/ratelimit"
+	"matiks/leaderboard/internal/scheduler"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+	"matiks/leaderboard/internal/webhook"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+const serveUsage = `Run the HTTP API.
+
+Usage:
+  leaderboard serve
+`
+
+// runServe implements the serve command
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, serveUsage) }
+	fs.Parse(args)
+
+	// 0. Set up tracing before any instrumented client is created
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		log.Fatal("Failed to set up tracing:", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Failed to flush traces: %v", err)
+		}
+	}()
+
+	// 1. Connect to the database (schema must be migrated) and Redis
+	a, err := app.Open(context.Background(), app.Options{Redis: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer a.Close()
+
+	// Authentication: API keys always, JWTs when a JWKS file is configured
+	authConfig := config.LoadAuth()
+	jwtVerifier, err := authConfig.JWTVerifier()
+	if err != nil {
+		log.Fatal("Failed to load JWKS file:", err)
+	}
+
+	// 2. Initialize layers (bottom to top)
+	// Repository layer (Redis is nil if not configured; its circuit breaker
+	// bypasses Redis while it is down)
+	userRepo, apiKeyRepo, healthRepo, redisRepo := a.UserRepo, a.APIKeyRepo, a.HealthRepo, a.RedisRepo
+
+	// Page cache for hot leaderboard pages; rating changes invalidate the
+	// pages they touch on every instance
+	cacheConfig := config.LoadCache()
+	pageCache := cache.NewPageCache(cacheConfig.TTL, cacheConfig.MaxPage)
+	cacheBroadcaster := cache.NewBroadcaster(pageCache, redisRepo)
+	go cacheBroadcaster.Listen(context.Background())
+
+	// Achievement definitions come from ACHIEVEMENTS_FILE or the built-in set
+	achievementDefinitions, err := config.LoadAchievements().Definitions()
+	if err != nil {
+		log.Fatal("Failed to load achievements:", err)
+	}
+
+	// Service layer
+	achievementService := service.NewAchievementService(userRepo, redisRepo, a.AchievementRepo, achievementDefinitions)
+	leaderboardServiceInterface := service.NewLeaderboardService(userRepo, redisRepo, pageCache)
+	userServiceInterface := service.NewUserService(userRepo, redisRepo, cacheBroadcaster, config.LoadProfile(), achievementService)
+	peakService := service.NewPeakService(userRepo, redisRepo)
+	// Player rating updates are screened by anti-cheat rules in the workers
+	antiCheatService := service.NewAntiCheatService(userRepo, a.AntiCheatRepo, config.LoadAntiCheat())
+	updateService := service.NewUpdateService(userRepo, redisRepo, peakService, antiCheatService)
+	authService := service.NewAuthService(apiKeyRepo, jwtVerifier)
+	syncService := service.NewSyncService(userRepo, redisRepo, cacheBroadcaster)
+	auditService := service.NewAuditService(a.AuditRepo)
+	healthService := service.NewHealthService(healthRepo, userRepo, redisRepo, syncService, updateService, config.LoadHealth())
+
+	// Sync Redis once it answers, and again whenever it recovers from an outage
+	syncService.Start(context.Background(), config.LoadRedisHealth().ProbeInterval)
+
+	// Decay or hide inactive users, writing through the update path
+	decayConfig := config.LoadDecay()
+	decayService := service.NewDecayService(userRepo, redisRepo, a.DecayRepo, updateService, cacheBroadcaster, decayConfig)
+
+	// Webhooks for rank milestones; every instance sends queued deliveries
+	webhookConfig := config.LoadWebhooks()
+	webhookDispatcher := webhook.NewDispatcher(a.WebhookRepo, webhookConfig)
+	webhookDispatcher.Start(context.Background())
+	webhookService := service.NewWebhookService(a.WebhookRepo, userRepo, redisRepo, webhookDispatcher, webhookConfig)
+
+	// Background jobs; every instance schedules them and a Redis lock picks
+	// which one runs each activation
+	jobScheduler := scheduler.New(a.JobRepo, redisRepo)
+	err = registerJobs(context.Background(), jobScheduler, jobServices{
+		redisRepo:    redisRepo,
+		update:       updateService,
+		sync:         syncService,
+		snapshot:     service.NewSnapshotService(a.SnapshotRepo, config.LoadSnapshots()),
+		peak:         peakService,
+		decay:        decayService,
+		webhook:      webhookService,
+		achievements: achievementService,
+		decayMode:    decayConfig.Mode,
+	})
+	if err != nil {
+		log.Fatal("Failed to register jobs:", err)
+	}
+	jobScheduler.Start(context.Background())
+
+	updateService.Observe(func(ctx context.Context, change service.RatingChange) {
+		if change.Rejoined {
+			// A user came back onto the board, which shifts every page's total
+			cacheBroadcaster.InvalidateAll(ctx)
+			return
+		}
+		cacheBroadcaster.InvalidateRange(ctx, change.OldRating, change.NewRating)
+	})
+	updateService.Observe(webhookService.OnRatingChange)
+	updateService.Observe(achievementService.OnRatingChange)
+
+	// Every applied change goes to the event stream for downstream services
+	eventService := service.NewEventService(userRepo, redisRepo, config.LoadEvents())
+	if redisRepo != nil {
+		updateService.Observe(eventService.OnRatingChange)
+	}
+
+	// Type assertions to get concrete types for controllers
+	leaderboardService, ok := leaderboardServiceInterface.(*service.LeaderboardService)
+	if !ok {
+		log.Fatal("Failed to assert LeaderboardService type")
+	}
+
+	userService, ok := userServiceInterface.(*service.UserService)
+	if !ok {
+		log.Fatal("Failed to assert UserService type")
+	}
+
+	// Controller layer
+	leaderboardController := controllers.NewLeaderboardController(leaderboardService)
+	userController := controllers.NewUserController(userService)
+	updateController := controllers.NewUpdateController(updateService)
+	authController := controllers.NewAuthController(authService)
+	adminController := controllers.NewAdminController(syncService, service.NewImportService(userRepo, syncService), service.NewExportService(userRepo), decayService)
+	healthController := controllers.NewHealthController(healthService)
+	peakController := controllers.NewPeakController(peakService)
+	jobController := controllers.NewJobController(jobScheduler)
+	auditController := controllers.NewAuditController(auditService)
+	moderationController := controllers.NewModerationController(service.NewModerationService(userRepo, updateService))
+	reviewController := controllers.NewReviewController(service.NewReviewService(userRepo, a.AntiCheatRepo, updateService))
+	webhookController := controllers.NewWebhookController(webhookService)
+	eventController := controllers.NewEventController(eventService)
+	achievementController := controllers.NewAchievementController(achievementService)
+	// Handler layer
+	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardController)
+	userHandler := handlers.NewUserHandler(userController)
+	updateHandler := handlers.NewUpdateHandler(updateController)
+	authHandler := handlers.NewAuthHandler(authController)
+	adminHandler := handlers.NewAdminHandler(adminController)
+	healthHandler := handlers.NewHealthHandler(healthController)
+	peakHandler := handlers.NewPeakHandler(peakController)
+	jobHandler := handlers.NewJobHandler(jobController)
+	auditHandler := handlers.NewAuditHandler(auditController)
+	moderationHandler := handlers.NewModerationHandler(moderationController)
+	reviewHandler := handlers.NewReviewHandler(reviewController)
+	webhookHandler := handlers.NewWebhookHandler(webhookController)
+	eventHandler := handlers.NewEventHandler(eventController)
+	achievementHandler := handlers.NewAchievementHandler(achievementController)
+	// 3. Setup Gin router
+	router := gin.Default()
+
+	// Client IPs key the rate limiter, so only trust X-Forwarded-For from known proxies
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+			log.Fatal("Invalid TRUSTED_PROXIES:", err)
+		}
+	}
+
+	// 4. Add CORS, request ID, metrics, tracing and error-mapping middleware
+	router.Use(cors.Default())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Metrics())
+	router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		// Scrapes and probes would otherwise drown out real traffic
+		switch r.URL.Path {
+		case "/metrics", "/livez", "/readyz", "/health":
+			return false
+		}
+		return true
+	})))
+	router.Use(middleware.Errors())
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(apperrors.NotFound("route %s %s not found", c.Request.Method, c.Request.URL.Path))
+	})
+
+	// 5. Setup routes
+	// Liveness and readiness probes; /health is kept as an alias of /readyz
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Readyz)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// API routes, each with its own request deadline and rate limit
+	timeouts := config.LoadTimeouts()
+	deadline := func(route string) gin.HandlerFunc {
+		return middleware.Timeout(timeouts.For(route))
+	}
+	limiter := ratelimit.New(redisRepo)
+	rateLimits := config.LoadRateLimits()
+	limit := func(route string) gin.HandlerFunc {
+		return middleware.RateLimit(limiter, route, rateLimits.For(route))
+	}
+	// Privileged actions are recorded in the audit log, successful or not
+	audited := func(action string) gin.HandlerFunc {
+		return middleware.Audit(auditService, action)
+	}
+
+	api := router.Group("/api/v1", middleware.Authenticate(authService))
+
+	// Public read routes, optionally behind the read scope
+	read := api.Group("")
+	if authConfig.RequireRead {
+		read.Use(middleware.RequireScope(auth.ScopeRead))
+	}
+	{
+		// Leaderboard routes
+		read.GET("/leaderboard", deadline(config.RouteLeaderboard), limit(config.RouteLeaderboard), leaderboardHandler.GetLeaderboard)
+		read.GET("/hall-of-fame", deadline(config.RouteHallOfFame), limit(config.RouteHallOfFame), peakHandler.HallOfFame)
+
+		// User routes
+		read.GET("/users/search", deadline(config.RouteUserSearch), limit(config.RouteUserSearch), userHandler.SearchUsers)
+		read.GET("/users/:username", deadline(config.RouteUserProfile), limit(config.RouteUserProfile), userHandler.GetProfile)
+		read.GET("/users/:username/rank", deadline(config.RouteUserRank), limit(config.RouteUserRank), userHandler.GetUserRank)
+		read.GET("/users/:username/achievements", deadline(config.RouteUserProfile), limit(config.RouteUserProfile), userHandler.GetAchievements)
+
+		// Achievements with how many players hold each
+		read.GET("/achievements", deadline(config.RouteAchievements), limit(config.RouteAchievements), achievementHandler.Stats)
+
+		// Replay of the event stream for consumers catching up
+		read.GET("/events", deadline(config.RouteEvents), limit(config.RouteEvents), eventHandler.Replay)
+	}
+
+	// Score submission routes
+	submit := api.Group("", middleware.RequireScope(auth.ScopeSubmitScores))
+	{
+		submit.POST("/users/:username/rating", deadline(config.RouteSubmitRating), limit(config.RouteSubmitRating), updateHandler.SubmitRating)
+		submit.POST("/users", deadline(config.RouteRegisterUser), limit(config.RouteRegisterUser), userHandler.Register)
+	}
+
+	// Admin routes
+	admin := api.Group("/admin", middleware.RequireScope(auth.ScopeAdmin))
+	{
+		admin.POST("/api-keys", deadline(config.RouteAdminAPIKeys), limit(config.RouteAdminAPIKeys), audited(audit.ActionAPIKeyCreate), authHandler.CreateAPIKey)
+		admin.GET("/api-keys", deadline(config.RouteAdminAPIKeys), limit(config.RouteAdminAPIKeys), authHandler.ListAPIKeys)
+		admin.DELETE("/api-keys/:id", deadline(config.RouteAdminAPIKeys), limit(config.RouteAdminAPIKeys), audited(audit.ActionAPIKeyRevoke), authHandler.RevokeAPIKey)
+
+		// User lifecycle; hidden, banned and deleted users leave the leaderboard
+		adminUsers := admin.Group("/users/:username", deadline(config.RouteAdminUsers), limit(config.RouteAdminUsers))
+		adminUsers.GET("", userHandler.GetUser)
+		adminUsers.PATCH("", audited(audit.ActionUserRename), userHandler.Rename)
+		adminUsers.DELETE("", audited(audit.ActionUserDelete), userHandler.Delete)
+		adminUsers.PUT("/ban", audited(audit.ActionUserBan), userHandler.Ban)
+		adminUsers.DELETE("/ban", audited(audit.ActionUserUnban), userHandler.Ban)
+		adminUsers.PUT("/hide", audited(audit.ActionUserHide), userHandler.Hide)
+		adminUsers.DELETE("/hide", audited(audit.ActionUserUnhide), userHandler.Hide)
+
+		// Rating corrections; each needs a reason and is written through the
+		// update path, so Redis and the page cache follow
+		adminUsers.PUT("/rating", audited(audit.ActionRatingSet), moderationHandler.SetRating)
+		adminUsers.POST("/rating/adjust", audited(audit.ActionRatingAdjust), moderationHandler.AdjustRating)
+		adminUsers.POST("/rating/rollback", audited(audit.ActionRatingRollback), moderationHandler.Rollback)
+		admin.POST("/ratings/rollback", deadline(config.RouteAdminRatings), limit(config.RouteAdminRatings), audited(audit.ActionBulkRollback), moderationHandler.BulkRollback)
+
+		// Anti-cheat review queue; approving a held update applies it
+		adminReviews := admin.Group("/reviews", deadline(config.RouteAdminReviews), limit(config.RouteAdminReviews))
+		adminReviews.GET("", reviewHandler.List)
+		adminReviews.POST("/:id/approve", audited(audit.ActionReviewApprove), reviewHandler.Approve)
+		adminReviews.POST("/:id/reject", audited(audit.ActionReviewReject), reviewHandler.Reject)
+
+		// Webhooks for leaderboard milestones and their delivery log
+		adminWebhooks := admin.Group("/webhooks", deadline(config.RouteAdminWebhooks), limit(config.RouteAdminWebhooks))
+		adminWebhooks.GET("", webhookHandler.List)
+		adminWebhooks.POST("", audited(audit.ActionWebhookCreate), webhookHandler.Create)
+		adminWebhooks.DELETE("/:id", audited(audit.ActionWebhookDelete), webhookHandler.Delete)
+		adminWebhooks.GET("/:id/deliveries", webhookHandler.Deliveries)
+		adminWebhooks.POST("/:id/test", audited(audit.ActionWebhookTest), webhookHandler.Test)
+
+		// Sync returns 503 while Redis is down or not configured
+		admin.POST("/sync-redis", deadline(config.RouteAdminSyncRedis), limit(config.RouteAdminSyncRedis), audited(audit.ActionRedisSync), adminHandler.SyncRedis)
+		admin.POST("/import", deadline(config.RouteAdminImport), limit(config.RouteAdminImport), audited(audit.ActionUsersImport), adminHandler.Import)
+		admin.GET("/export", deadline(config.RouteAdminExport), limit(config.RouteAdminExport), audited(audit.ActionUsersExport), adminHandler.Export)
+		admin.POST("/decay/run", deadline(config.RouteAdminDecay), limit(config.RouteAdminDecay), audited(audit.ActionDecayRun), adminHandler.RunDecay)
+		admin.GET("/decay/runs", deadline(config.RouteAdminDecay), limit(config.RouteAdminDecay), adminHandler.DecayRuns)
+		admin.POST("/simulate-updates", deadline(config.RouteAdminSimulation), limit(config.RouteAdminSimulation), audited(audit.ActionSimulateUpdates), updateHandler.SimulateUpdates)
+
+		// Scheduled jobs; a manual run is accepted and continues in the background
+		adminJobs := admin.Group("/jobs", deadline(config.RouteAdminJobs), limit(config.RouteAdminJobs))
+		adminJobs.GET("", jobHandler.ListJobs)
+		adminJobs.GET("/:name/runs", jobHandler.Runs)
+		adminJobs.POST("/:name/run", audited(audit.ActionJobRun), jobHandler.Trigger)
+		adminJobs.POST("/:name/pause", audited(audit.ActionJobPause), jobHandler.Pause)
+		adminJobs.POST("/:name/resume", audited(audit.ActionJobResume), jobHandler.Resume)
+
+		// Audit log of the actions above
+		admin.GET("/audit", deadline(config.RouteAdminAudit), limit(config.RouteAdminAudit), auditHandler.List)
+	}
+
+	// 6. Start server
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080" // Default for local development
+	}
+	log.Printf("Server starting on port %s", port)
+	if err := router.Run(":" + port); err != nil {
+		log.Fatal("Failed to start server:", err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"matiks/leaderboard/internal/app"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/service"
+)
+
+const syncRedisUsage = `Rebuild the Redis leaderboard from the database.
+
+Usage:
+  leaderboard sync-redis
+`
+
+// runSyncRedis implements the sync-redis command
+func runSyncRedis(args []string) {
+	fs := flag.NewFlagSet("sync-redis", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, syncRedisUsage) }
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	a, syncService := openSync(ctx)
+	defer a.Close()
+
+	var count int
+	err := auditCLI(ctx, a, audit.ActionRedisSync, "", func(ctx context.Context) (err error) {
+		count, err = syncService.SyncRedis(ctx)
+		return err
+	})
+	if err != nil {
+		log.Fatal("Sync failed: ", err)
+	}
+	fmt.Printf("Synced %d users to Redis\n", count)
+}
+
+// openSync connects to the database and a reachable Redis, for commands that
+// work on both
+func openSync(ctx context.Context) (*app.App, *service.SyncService) {
+	a, err := app.Open(ctx, app.Options{Redis: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if a.RedisRepo == nil {
+		log.Fatal("REDIS_URL is not set")
+	}
+	if err := a.RedisRepo.Connect(ctx); err != nil {
+		log.Fatal("Failed to connect to Redis: ", err)
+	}
+
+	// No local page cache to invalidate; servers' cached pages expire on their own
+	broadcaster := cache.NewBroadcaster(cache.NewPageCache(0, 0), a.RedisRepo)
+	return a, service.NewSyncService(a.UserRepo, a.RedisRepo, broadcaster)
+}
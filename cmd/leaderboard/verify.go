@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const verifyUsage = `Compare the Redis leaderboard with the database. Exits with status 1
+if they differ.
+
+Usage:
+  leaderboard verify [-show N]
+
+Flags:
+`
+
+// runVerify implements the verify command
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	show := fs.Int("show", 10, "how many differences of each kind to list")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, verifyUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	a, syncService := openSync(ctx)
+	defer a.Close()
+
+	report, err := syncService.Verify(ctx)
+	if err != nil {
+		log.Fatal("Verify failed: ", err)
+	}
+
+	fmt.Printf("Database users: %d\n", report.DatabaseUsers)
+	fmt.Printf("Redis users:    %d\n", report.RedisUsers)
+	fmt.Printf("Missing in Redis: %d\n", len(report.MissingInRedis))
+	for _, username := range limit(report.MissingInRedis, *show) {
+		fmt.Printf("  %s\n", username)
+	}
+	fmt.Printf("Extra in Redis:   %d\n", len(report.ExtraInRedis))
+	for _, member := range limit(report.ExtraInRedis, *show) {
+		fmt.Printf("  member %s\n", member)
+	}
+	fmt.Printf("Rating mismatches: %d\n", len(report.Mismatched))
+	for _, m := range limit(report.Mismatched, *show) {
+		fmt.Printf("  %s: database %d, redis %d\n", m.Username, m.DBRating, m.RedisRating)
+	}
+	fmt.Printf("Stale profiles:   %d\n", len(report.StaleProfiles))
+	for _, username := range limit(report.StaleProfiles, *show) {
+		fmt.Printf("  %s\n", username)
+	}
+
+	if !report.InSync() {
+		fmt.Println("\nRedis is out of sync; run `leaderboard sync-redis` to rebuild it")
+		a.Close()
+		os.Exit(1)
+	}
+	fmt.Println("\nRedis is in sync")
+}
+
+func limit[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
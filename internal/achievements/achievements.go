@@ -0,0 +1,131 @@
+// Package achievements defines the badges players earn. Definitions are
+// declarative: each names one of a few kinds of criteria and its
+// parameters, and is loaded from a JSON file or taken from Defaults.
+package achievements
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// Kinds of criteria
+const (
+	// KindRating is earned by reaching a rating of at least Rating
+	KindRating = "rating"
+	// KindRankClimb is earned by climbing at least Ranks places within Window
+	KindRankClimb = "rank_climb"
+	// KindTopPercent is earned by staying in the top Percent of ranked
+	// players for Hold
+	KindTopPercent = "top_percent"
+)
+
+// Defaults are used when no definitions file is configured
+var Defaults = []Definition{
+	{ID: "rating_3000", Name: "Elite", Description: "Reached a rating of 3000", Kind: KindRating, Rating: 3000},
+	{ID: "climb_100_day", Name: "Rocket", Description: "Climbed 100 ranks in a day", Kind: KindRankClimb, Ranks: 100, Window: Duration(24 * time.Hour)},
+	{ID: "top_1_percent_week", Name: "Top 1%", Description: "Stayed in the top 1% for a week", Kind: KindTopPercent, Percent: 1, Hold: Duration(7 * 24 * time.Hour)},
+}
+
+// idPattern keeps IDs usable in URLs and stable as storage keys
+var idPattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+// Definition is one achievement. Only the parameters of its Kind are set.
+// ID is what awards are stored under, so renaming it orphans them.
+type Definition struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Kind        string   `json:"kind"`
+	Rating      int      `json:"rating,omitempty"`
+	Ranks       int      `json:"ranks,omitempty"`
+	Window      Duration `json:"window,omitempty"`
+	Percent     float64  `json:"percent,omitempty"`
+	Hold        Duration `json:"hold,omitempty"`
+}
+
+// Duration is a time.Duration written in Go syntax in JSON, e.g. "24h"
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"24h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Parse reads and validates a JSON array of definitions
+func Parse(data []byte) ([]Definition, error) {
+	var definitions []Definition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(definitions))
+	for _, d := range definitions {
+		if err := d.validate(); err != nil {
+			return nil, err
+		}
+		if seen[d.ID] {
+			return nil, fmt.Errorf("achievement %q is defined twice", d.ID)
+		}
+		seen[d.ID] = true
+	}
+	return definitions, nil
+}
+
+func (d Definition) validate() error {
+	if !idPattern.MatchString(d.ID) {
+		return fmt.Errorf("achievement id %q must be 1-64 lowercase letters, digits or underscores", d.ID)
+	}
+	if d.Name == "" {
+		return fmt.Errorf("achievement %q needs a name", d.ID)
+	}
+	switch d.Kind {
+	case KindRating:
+		if d.Rating <= 0 {
+			return fmt.Errorf("achievement %q: rating must be positive", d.ID)
+		}
+	case KindRankClimb:
+		if d.Ranks <= 0 || d.Window <= 0 {
+			return fmt.Errorf("achievement %q: ranks and window must be positive", d.ID)
+		}
+	case KindTopPercent:
+		if d.Percent <= 0 || d.Percent > 100 || d.Hold <= 0 {
+			return fmt.Errorf("achievement %q: percent must be in (0, 100] and hold positive", d.ID)
+		}
+	default:
+		return fmt.Errorf("achievement %q: unknown kind %q", d.ID, d.Kind)
+	}
+	return nil
+}
+
+// Criteria describes what earns the achievement
+func (d Definition) Criteria() string {
+	switch d.Kind {
+	case KindRating:
+		return fmt.Sprintf("reach a rating of %d", d.Rating)
+	case KindRankClimb:
+		return fmt.Sprintf("climb %d ranks within %v", d.Ranks, time.Duration(d.Window))
+	case KindTopPercent:
+		return fmt.Sprintf("stay in the top %v%% for %v", d.Percent, time.Duration(d.Hold))
+	}
+	return ""
+}
+
+// TopRank is the worst rank still in the top Percent of total ranked
+// players. At least the leader always qualifies.
+func (d Definition) TopRank(total int64) int64 {
+	return max(int64(math.Ceil(float64(total)*d.Percent/100)), 1)
+}
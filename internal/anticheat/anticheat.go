@@ -0,0 +1,170 @@
+// Package anticheat screens rating updates against a set of rules before
+// they are applied. Each rule carries the action taken when it fires; when
+// several fire, the most severe action wins.
+package anticheat
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"matiks/leaderboard/internal/models"
+)
+
+// severity orders actions from least to most severe
+var severity = map[string]int{
+	models.AntiCheatFlag:   1,
+	models.AntiCheatHold:   2,
+	models.AntiCheatReject: 3,
+}
+
+// CheckedSources are the rating sources whose updates are screened, and
+// whose history the rules judge a user by. Moderator, decay, import and
+// simulated changes are trusted.
+var CheckedSources = []string{models.RatingSourceSubmission, models.RatingSourceMatch}
+
+// Checked reports whether updates from source are screened
+func Checked(source string) bool {
+	return slices.Contains(CheckedSources, source)
+}
+
+// History is a user's recent checked rating changes
+type History struct {
+	// HourlyGain is the net change over the last hour
+	HourlyGain int
+	// Deltas are the most recent changes, newest first
+	Deltas []int
+}
+
+// Update is a rating change about to be applied to a locked user
+type Update struct {
+	OldRating int
+	NewRating int
+	History   History
+}
+
+// Delta is the change the update makes
+func (u Update) Delta() int {
+	return u.NewRating - u.OldRating
+}
+
+// Rule checks one property of an update
+type Rule interface {
+	Name() string
+	// Check returns a description of the violation, or "" if there is none
+	Check(update Update) string
+}
+
+// Binding attaches an action to a rule
+type Binding struct {
+	Rule   Rule
+	Action string
+}
+
+// Verdict is the outcome of screening one update. Action is empty when no
+// rule fired.
+type Verdict struct {
+	Action string
+	Hits   []models.RuleHit
+}
+
+// Allows reports whether the update may be applied now
+func (v Verdict) Allows() bool {
+	return v.Action == "" || v.Action == models.AntiCheatFlag
+}
+
+// Engine evaluates every bound rule against an update
+type Engine struct {
+	bindings []Binding
+}
+
+// New creates an Engine from rule bindings
+func New(bindings ...Binding) *Engine {
+	return &Engine{bindings: bindings}
+}
+
+// Evaluate runs every rule and returns the most severe action among those
+// that fired, with every hit
+func (e *Engine) Evaluate(update Update) Verdict {
+	var verdict Verdict
+	for _, b := range e.bindings {
+		detail := b.Rule.Check(update)
+		if detail == "" {
+			continue
+		}
+		verdict.Hits = append(verdict.Hits, models.RuleHit{Rule: b.Rule.Name(), Action: b.Action, Detail: detail})
+		if severity[b.Action] > severity[verdict.Action] {
+			verdict.Action = b.Action
+		}
+	}
+	return verdict
+}
+
+// MaxDelta fires when one update moves a rating by more than Limit either way
+type MaxDelta struct {
+	Limit int
+}
+
+func (MaxDelta) Name() string { return "max_delta" }
+
+func (r MaxDelta) Check(update Update) string {
+	if delta := update.Delta(); delta > r.Limit || -delta > r.Limit {
+		return fmt.Sprintf("change of %+d exceeds %d", delta, r.Limit)
+	}
+	return ""
+}
+
+// MaxHourlyGain fires when an increase would take the user's net gain over
+// the last hour above Limit
+type MaxHourlyGain struct {
+	Limit int
+}
+
+func (MaxHourlyGain) Name() string { return "max_hourly_gain" }
+
+func (r MaxHourlyGain) Check(update Update) string {
+	delta := update.Delta()
+	if delta <= 0 {
+		return ""
+	}
+	if gain := update.History.HourlyGain + delta; gain > r.Limit {
+		return fmt.Sprintf("gain of %d in the last hour exceeds %d", gain, r.Limit)
+	}
+	return ""
+}
+
+// minOutlierStdDev keeps a user with near-identical past changes from having
+// every small variation reported
+const minOutlierStdDev = 10.0
+
+// Outlier fires when the change is more than ZScore standard deviations from
+// the mean of the user's recent changes. Users with fewer than MinSamples
+// changes are not judged.
+type Outlier struct {
+	ZScore     float64
+	MinSamples int
+}
+
+func (Outlier) Name() string { return "outlier" }
+
+func (r Outlier) Check(update Update) string {
+	deltas := update.History.Deltas
+	if len(deltas) < r.MinSamples || len(deltas) == 0 {
+		return ""
+	}
+	var sum float64
+	for _, d := range deltas {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(deltas))
+	var squares float64
+	for _, d := range deltas {
+		squares += (float64(d) - mean) * (float64(d) - mean)
+	}
+	stddev := math.Max(math.Sqrt(squares/float64(len(deltas))), minOutlierStdDev)
+
+	if z := (float64(update.Delta()) - mean) / stddev; math.Abs(z) > r.ZScore {
+		return fmt.Sprintf("change of %+d is %.1f standard deviations from the mean %+.1f of the last %d", update.Delta(), z, mean, len(deltas))
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/migrate"
+	"matiks/leaderboard/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// App holds the connections and repositories shared by every CLI command
+type App struct {
+	DB              *gorm.DB
+	UserRepo        *repository.UserRepository
+	APIKeyRepo      *repository.APIKeyRepository
+	HealthRepo      *repository.HealthRepository
+	DecayRepo       *repository.DecayRepository
+	JobRepo         *repository.JobRepository
+	SnapshotRepo    *repository.SnapshotRepository
+	AuditRepo       *repository.AuditRepository
+	AntiCheatRepo   *repository.AntiCheatRepository
+	WebhookRepo     *repository.WebhookRepository
+	AchievementRepo *repository.AchievementRepository
+	// RedisRepo is nil when Redis was not requested or REDIS_URL is not set.
+	// Its circuit starts open; see RedisRepository.
+	RedisRepo *repository.RedisRepository
+}
+
+// Options selects what Open connects to
+type Options struct {
+	// Redis creates the Redis repository when REDIS_URL is set
+	Redis bool
+	// SkipSchemaCheck allows a database with pending migrations; only the
+	// migrate command needs it
+	SkipSchemaCheck bool
+}
+
+// Open connects to Postgres, makes sure the schema is current and, if asked,
+// sets up Redis
+func Open(ctx context.Context, opts Options) (*App, error) {
+	db, err := config.ConnectDB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if !opts.SkipSchemaCheck {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database handle: %w", err)
+		}
+		if err := migrate.EnsureCurrent(ctx, sqlDB); err != nil {
+			return nil, err
+		}
+	}
+
+	a := &App{
+		DB:              db,
+		UserRepo:        repository.NewUserRepository(db),
+		APIKeyRepo:      repository.NewAPIKeyRepository(db),
+		HealthRepo:      repository.NewHealthRepository(db),
+		DecayRepo:       repository.NewDecayRepository(db),
+		JobRepo:         repository.NewJobRepository(db),
+		SnapshotRepo:    repository.NewSnapshotRepository(db),
+		AuditRepo:       repository.NewAuditRepository(db),
+		AntiCheatRepo:   repository.NewAntiCheatRepository(db),
+		WebhookRepo:     repository.NewWebhookRepository(db),
+		AchievementRepo: repository.NewAchievementRepository(db),
+	}
+
+	if opts.Redis {
+		// The client connects lazily, so a Redis that is down now is picked
+		// up by the health monitor once it comes up
+		redisClient, err := config.ConnectRedis()
+		if err != nil {
+			log.Printf("Warning: Redis not configured: %v (continuing without Redis)", err)
+		} else {
+			a.RedisRepo = repository.NewRedisRepository(redisClient, config.LoadRedisHealth().FailureThreshold)
+		}
+	}
+	return a, nil
+}
+
+// Close releases the database and Redis connections
+func (a *App) Close() {
+	if sqlDB, err := a.DB.DB(); err == nil {
+		sqlDB.Close()
+	}
+	if a.RedisRepo != nil {
+		a.RedisRepo.Close()
+	}
+}
@@ -0,0 +1,160 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Kind classifies an error for the API layer
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindValidation   Kind = "validation_error"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindConflict     Kind = "conflict"
+	KindUnavailable  Kind = "unavailable"
+	KindQueueFull    Kind = "queue_full"
+	KindRateLimited  Kind = "rate_limited"
+	KindTimeout      Kind = "timeout"
+	KindCanceled     Kind = "canceled"
+	KindInternal     Kind = "internal_error"
+)
+
+// StatusClientClosedRequest is the de-facto status for requests the client abandoned
+const StatusClientClosedRequest = 499
+
+// Error is a domain error returned by the service layer.
+// Message is safe to show to API clients; Err holds the underlying cause for logs.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetail attaches a key/value pair to the error details
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Wrap attaches an underlying cause to the error
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func newError(kind Kind, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// NotFound reports a missing resource
+func NotFound(format string, args ...interface{}) *Error {
+	return newError(KindNotFound, format, args...)
+}
+
+// Validation reports invalid input
+func Validation(format string, args ...interface{}) *Error {
+	return newError(KindValidation, format, args...)
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(format string, args ...interface{}) *Error {
+	return newError(KindUnauthorized, format, args...)
+}
+
+// Forbidden reports valid credentials that lack the required permission
+func Forbidden(format string, args ...interface{}) *Error {
+	return newError(KindForbidden, format, args...)
+}
+
+// Conflict reports a request that clashes with current state
+func Conflict(format string, args ...interface{}) *Error {
+	return newError(KindConflict, format, args...)
+}
+
+// Unavailable reports a dependency that can't serve the request right now
+func Unavailable(format string, args ...interface{}) *Error {
+	return newError(KindUnavailable, format, args...)
+}
+
+// QueueFull reports that the update queue rejected work
+func QueueFull(format string, args ...interface{}) *Error {
+	return newError(KindQueueFull, format, args...)
+}
+
+// RateLimited reports a client that exceeded its request budget
+func RateLimited(format string, args ...interface{}) *Error {
+	return newError(KindRateLimited, format, args...)
+}
+
+// Internal wraps an unexpected error; its cause is never shown to clients
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: "internal server error", Err: err}
+}
+
+// As returns err as an *Error, classifying context errors and anything
+// unrecognised along the way
+func As(err error) *Error {
+	var appErr *Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindTimeout, Message: "request timed out", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: KindCanceled, Message: "request canceled", Err: err}
+	default:
+		return Internal(err)
+	}
+}
+
+// Is reports whether err is a domain error of the given kind
+func Is(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
+
+// HTTPStatus maps an error kind to its HTTP status code
+func HTTPStatus(kind Kind) int {
+	switch kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindConflict:
+		return http.StatusConflict
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnavailable, KindQueueFull:
+		return http.StatusServiceUnavailable
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	case KindCanceled:
+		return StatusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
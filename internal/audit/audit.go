@@ -0,0 +1,169 @@
+// Package audit collects what a privileged action changed while it runs so
+// the action can be recorded in the audit log when it finishes. The caller
+// that owns the action (the audit middleware, or a CLI command) starts a
+// Record; services describe the target and its state with SetTarget and
+// SetChange, which do nothing when no Record was started.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/auth"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/requestid"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionAPIKeyCreate    = "api_key.create"
+	ActionAPIKeyRevoke    = "api_key.revoke"
+	ActionUserRename      = "user.rename"
+	ActionUserDelete      = "user.delete"
+	ActionUserBan         = "user.ban"
+	ActionUserUnban       = "user.unban"
+	ActionUserHide        = "user.hide"
+	ActionUserUnhide      = "user.unhide"
+	ActionRatingSet       = "user.rating_set"
+	ActionRatingAdjust    = "user.rating_adjust"
+	ActionRatingRollback  = "user.rating_rollback"
+	ActionBulkRollback    = "ratings.bulk_rollback"
+	ActionReviewApprove   = "review.approve"
+	ActionReviewReject    = "review.reject"
+	ActionWebhookCreate   = "webhook.create"
+	ActionWebhookDelete   = "webhook.delete"
+	ActionWebhookTest     = "webhook.test"
+	ActionRedisSync       = "redis.sync"
+	ActionUsersImport     = "users.import"
+	ActionUsersExport     = "users.export"
+	ActionDecayRun        = "decay.run"
+	ActionSimulateUpdates = "ratings.simulate"
+	ActionJobRun          = "job.run"
+	ActionJobPause        = "job.pause"
+	ActionJobResume       = "job.resume"
+)
+
+// TargetType returns the kind of target an action applies to, the part of
+// the action before the dot
+func TargetType(action string) string {
+	targetType, _, _ := strings.Cut(action, ".")
+	return targetType
+}
+
+// Record accumulates the details of one action
+type Record struct {
+	mu         sync.Mutex
+	targetType string
+	target     string
+	before     interface{}
+	after      interface{}
+}
+
+type recordKey struct{}
+
+// Begin starts a Record for the action about to run on ctx
+func Begin(ctx context.Context) (context.Context, *Record) {
+	r := &Record{}
+	return context.WithValue(ctx, recordKey{}, r), r
+}
+
+func from(ctx context.Context) *Record {
+	r, _ := ctx.Value(recordKey{}).(*Record)
+	return r
+}
+
+// SetTarget names what the action applies to
+func SetTarget(ctx context.Context, targetType, target string) {
+	if r := from(ctx); r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.targetType, r.target = targetType, target
+	}
+}
+
+// SetChange records the target's state before and after the action. Either
+// may be nil. Values are stored as JSON, so secrets must be kept out of them.
+func SetChange(ctx context.Context, before, after interface{}) {
+	if r := from(ctx); r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.before, r.after = before, after
+	}
+}
+
+// Actor identifies who performed an action when there is no authenticated
+// principal, e.g. an operator running a CLI command
+type Actor struct {
+	ID   string
+	Name string
+}
+
+type actorKey struct{}
+
+// WithActor sets the actor for actions run on ctx without a principal
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the principal on ctx, then an actor set with WithActor,
+// then "anonymous"
+func ActorFrom(ctx context.Context) Actor {
+	if p := auth.PrincipalFrom(ctx); p != nil {
+		return Actor{ID: p.Subject, Name: p.Name}
+	}
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{ID: "anonymous"}
+}
+
+// Event builds the audit event for the action, its actor and request from
+// ctx, and the error it returned if any
+func (r *Record) Event(ctx context.Context, action string, err error) *models.AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	actor := ActorFrom(ctx)
+	event := &models.AuditEvent{
+		OccurredAt: time.Now(),
+		Actor:      actor.ID,
+		ActorName:  optional(actor.Name),
+		Action:     action,
+		TargetType: optional(r.targetType),
+		Target:     optional(r.target),
+		Before:     marshal(action, r.before),
+		After:      marshal(action, r.after),
+		Outcome:    models.AuditSuccess,
+		RequestID:  optional(requestid.From(ctx)),
+	}
+	if err != nil {
+		msg := err.Error()
+		event.Outcome, event.Error = models.AuditFailure, &msg
+	}
+	return event
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// marshal encodes a state for the event. A state that cannot be encoded is
+// logged and left out rather than losing the event.
+func marshal(action string, state interface{}) json.RawMessage {
+	if state == nil {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		log.Printf("Failed to encode audit state for %s: %v", action, err)
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix marks a bearer credential as an API key rather than a JWT
+const APIKeyPrefix = "lb_"
+
+// GenerateAPIKey returns a new random API key and its lookup prefix.
+// Keys look like lb_<8 hex prefix>_<48 hex secret>; only the prefix and the
+// SHA-256 hash are ever stored.
+func GenerateAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 28)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	encoded := hex.EncodeToString(buf)
+	prefix = encoded[:8]
+	return APIKeyPrefix + prefix + "_" + encoded[8:], prefix, nil
+}
+
+// HashAPIKey returns the hex SHA-256 digest stored for a key.
+// API keys carry 224 bits of entropy, so a fast hash is sufficient.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether a bearer credential has the API key format
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
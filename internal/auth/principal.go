@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Scope is a permission granted to an API key or token
+type Scope string
+
+const (
+	ScopeRead         Scope = "read"
+	ScopeSubmitScores Scope = "submit-scores"
+	ScopeAdmin        Scope = "admin"
+)
+
+// AllScopes lists every known scope
+var AllScopes = []Scope{ScopeRead, ScopeSubmitScores, ScopeAdmin}
+
+// Authentication methods recorded on a Principal
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller: "apikey:<id>" or the JWT "sub" claim
+	Subject string
+	// Name is a human-readable label (API key name or JWT subject)
+	Name   string
+	Method string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope.
+// The admin scope implies every other scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScopes parses a comma- or space-separated scope list, rejecting unknown scopes
+func ParseScopes(raw string) ([]Scope, error) {
+	fields := strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' })
+	scopes := make([]Scope, 0, len(fields))
+	seen := make(map[Scope]bool, len(fields))
+	for _, f := range fields {
+		scope := Scope(strings.TrimSpace(f))
+		if !isKnown(scope) {
+			return nil, fmt.Errorf("unknown scope %q", f)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// JoinScopes renders scopes in the comma-separated form stored in the database
+func JoinScopes(scopes []Scope) string {
+	parts := make([]string, len(scopes))
+	for i, s := range scopes {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, ",")
+}
+
+func isKnown(scope Scope) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal stores the authenticated principal on the context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored on the context, or nil
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
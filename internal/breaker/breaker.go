@@ -0,0 +1,105 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State of a circuit breaker
+type State int
+
+const (
+	// Closed lets calls through and counts consecutive failures
+	Closed State = iota
+	// Open rejects calls immediately until the dependency is probed healthy again
+	Open
+)
+
+func (s State) String() string {
+	if s == Open {
+		return "open"
+	}
+	return "closed"
+}
+
+// Breaker is a consecutive-failure circuit breaker.
+//
+// It has no half-open state of its own: while open, no caller traffic reaches
+// the dependency, and an external health probe calls Reset once the dependency
+// answers again. This keeps request latency flat during an outage, as no
+// request ever pays a timeout to find out the dependency is still down.
+type Breaker struct {
+	mu        sync.Mutex
+	state     State
+	failures  int
+	threshold int
+	changedAt time.Time
+	onChange  func(from, to State)
+}
+
+// New creates a breaker in the initial state that opens after threshold
+// consecutive failures. onChange, if non-nil, is called (outside the lock) on
+// every state transition.
+func New(threshold int, initial State, onChange func(from, to State)) *Breaker {
+	return &Breaker{state: initial, threshold: threshold, changedAt: time.Now(), onChange: onChange}
+}
+
+// Allow reports whether a call may proceed
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == Closed
+}
+
+// State returns the current state and when it was entered
+func (b *Breaker) State() (State, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state, b.changedAt
+}
+
+// Success records a successful call
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// Failure records a failed call, opening the breaker at the threshold
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	b.failures++
+	trip := b.state == Closed && b.failures >= b.threshold
+	b.mu.Unlock()
+
+	if trip {
+		b.transition(Open)
+	}
+}
+
+// Trip opens the breaker immediately, e.g. when a health probe fails
+func (b *Breaker) Trip() {
+	b.transition(Open)
+}
+
+// Reset closes the breaker, e.g. when a health probe succeeds
+func (b *Breaker) Reset() {
+	b.transition(Closed)
+}
+
+func (b *Breaker) transition(to State) {
+	b.mu.Lock()
+	from := b.state
+	if from == to {
+		b.mu.Unlock()
+		return
+	}
+	b.state = to
+	b.failures = 0
+	b.changedAt = time.Now()
+	b.mu.Unlock()
+
+	if b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
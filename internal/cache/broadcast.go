@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"matiks/leaderboard/internal/repository"
+)
+
+// Broadcaster applies invalidations locally and relays them to every other
+// instance through Redis pub/sub, so each instance's in-process cache drops
+// the same pages. Without Redis, or while its circuit is open, it only
+// invalidates locally.
+type Broadcaster struct {
+	cache      *PageCache
+	redisRepo  *repository.RedisRepository
+	instanceID string
+}
+
+func NewBroadcaster(cache *PageCache, redisRepo *repository.RedisRepository) *Broadcaster {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return &Broadcaster{cache: cache, redisRepo: redisRepo, instanceID: hex.EncodeToString(id)}
+}
+
+// InvalidateRange drops pages overlapping [a, b] here and on other instances
+func (b *Broadcaster) InvalidateRange(ctx context.Context, lo, hi int) {
+	b.cache.InvalidateRange(lo, hi)
+	b.publish(ctx, fmt.Sprintf("%s|%d|%d", b.instanceID, lo, hi))
+}
+
+// InvalidateAll drops every page here and on other instances
+func (b *Broadcaster) InvalidateAll(ctx context.Context) {
+	b.cache.InvalidateAll()
+	b.publish(ctx, b.instanceID+"|all")
+}
+
+func (b *Broadcaster) publish(ctx context.Context, message string) {
+	// While Redis is down, other instances re-sync and drop their own pages
+	// when it comes back
+	if !b.redisRepo.Available() || !b.cache.Enabled() {
+		return
+	}
+	if err := b.redisRepo.PublishCacheInvalidation(ctx, message); err != nil {
+		log.Printf("Failed to broadcast cache invalidation: %v", err)
+	}
+}
+
+// Listen applies invalidations published by other instances until ctx is canceled
+func (b *Broadcaster) Listen(ctx context.Context) {
+	if b.redisRepo == nil || !b.cache.Enabled() {
+		return
+	}
+	for message := range b.redisRepo.SubscribeCacheInvalidations(ctx) {
+		parts := strings.Split(message, "|")
+		if len(parts) < 2 || parts[0] == b.instanceID {
+			continue
+		}
+		if parts[1] == "all" {
+			b.cache.InvalidateAll()
+			continue
+		}
+		if len(parts) != 3 {
+			continue
+		}
+		lo, errLo := strconv.Atoi(parts[1])
+		hi, errHi := strconv.Atoi(parts[2])
+		if errLo != nil || errHi != nil {
+			continue
+		}
+		b.cache.InvalidateRange(lo, hi)
+	}
+}
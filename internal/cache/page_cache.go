@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+)
+
+// Page is a serialized leaderboard page ready to be written to the client
+type Page struct {
+	Body []byte
+	ETag string
+
+	// Score range covered by the page; a rating change touching this range
+	// can reorder or re-rank the page
+	MinScore int
+	MaxScore int
+	// Empty pages (past the end of the board) only change when users are added or removed
+	Empty bool
+
+	expires time.Time
+}
+
+type pageKey struct {
+	page  int
+	limit int
+}
+
+// PageCache caches serialized leaderboard pages for a short TTL and drops
+// exactly the pages whose score range a rating change touches.
+//
+// Every invalidation bumps a version counter. A page computed while an
+// invalidation was in flight is not stored, so a page read before an update
+// can never outlive the update's invalidation.
+type PageCache struct {
+	mu      sync.Mutex
+	pages   map[pageKey]*Page
+	version uint64
+
+	ttl     time.Duration
+	maxPage int
+}
+
+// NewPageCache caches pages 1..maxPage for ttl. A zero ttl disables caching.
+func NewPageCache(ttl time.Duration, maxPage int) *PageCache {
+	return &PageCache{
+		pages:   make(map[pageKey]*Page),
+		ttl:     ttl,
+		maxPage: maxPage,
+	}
+}
+
+// Enabled reports whether the cache stores anything
+func (c *PageCache) Enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// Cacheable reports whether a page number is hot enough to be cached
+func (c *PageCache) Cacheable(page int) bool {
+	return c.Enabled() && page <= c.maxPage
+}
+
+// Get returns a fresh cached page
+func (c *PageCache) Get(page, limit int) (*Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.pages[pageKey{page, limit}]
+	if !ok || time.Now().After(p.expires) {
+		metrics.PageCacheRequests.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	metrics.PageCacheRequests.WithLabelValues("hit").Inc()
+	return p, true
+}
+
+// Version returns the current invalidation version; pass it to Set
+func (c *PageCache) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Set stores a page computed after Version returned version.
+// The page is dropped if anything was invalidated in the meantime.
+func (c *PageCache) Set(page, limit int, p *Page, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		return
+	}
+	p.expires = time.Now().Add(c.ttl)
+	c.pages[pageKey{page, limit}] = p
+}
+
+// InvalidateRange drops pages whose score range overlaps [a, b].
+// A rating change from old to new reorders exactly the entries between the
+// two ratings, so callers pass the old and new rating in either order.
+func (c *PageCache) InvalidateRange(a, b int) {
+	lo, hi := a, b
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	for key, p := range c.pages {
+		if !p.Empty && p.MinScore <= hi && p.MaxScore >= lo {
+			delete(c.pages, key)
+			metrics.PageCacheInvalidations.WithLabelValues("range").Inc()
+		}
+	}
+}
+
+// InvalidateAll drops every page, e.g. after users are added or removed,
+// which changes the total on every page
+func (c *PageCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	metrics.PageCacheInvalidations.WithLabelValues("all").Add(float64(len(c.pages)))
+	c.pages = make(map[pageKey]*Page)
+}
+
+// ETag returns a strong entity tag for a serialized body
+func ETag(body []byte) string {
+	sum := sha1.Sum(body)
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"matiks/leaderboard/internal/achievements"
+)
+
+// AchievementsConfig points at the achievement definitions
+type AchievementsConfig struct {
+	// File is a JSON array of definitions; the built-in ones are used
+	// when it is empty
+	File string
+}
+
+// LoadAchievements reads ACHIEVEMENTS_FILE
+func LoadAchievements() AchievementsConfig {
+	return AchievementsConfig{File: os.Getenv("ACHIEVEMENTS_FILE")}
+}
+
+// Definitions loads and validates the configured definitions
+func (c AchievementsConfig) Definitions() ([]achievements.Definition, error) {
+	if c.File == "" {
+		return achievements.Defaults, nil
+	}
+	data, err := os.ReadFile(c.File)
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := achievements.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.File, err)
+	}
+	return definitions, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"matiks/leaderboard/internal/models"
+)
+
+// AntiCheatRule is one anti-cheat rule's threshold and the action taken when
+// it fires. A rule with a zero threshold is off.
+type AntiCheatRule struct {
+	Threshold float64
+	Action    string
+}
+
+// AntiCheatConfig controls the rules queued rating updates are screened with
+type AntiCheatConfig struct {
+	// MaxDelta limits how far one update can move a rating
+	MaxDelta AntiCheatRule
+	// MaxHourlyGain limits a user's net gain over any hour
+	MaxHourlyGain AntiCheatRule
+	// Outlier is the z-score against the user's recent changes above which
+	// an update is an outlier
+	Outlier AntiCheatRule
+	// OutlierSamples is how many recent changes the outlier rule compares
+	// against; users with fewer are not judged
+	OutlierSamples int
+}
+
+// LoadAntiCheat reads ANTICHEAT_MAX_DELTA (default 500),
+// ANTICHEAT_MAX_HOURLY_GAIN (default 1000) and ANTICHEAT_OUTLIER_ZSCORE
+// (default 4), with ANTICHEAT_OUTLIER_SAMPLES (default 20). Each rule's
+// action is set with ANTICHEAT_<RULE>_ACTION: reject, hold or flag (defaults
+// hold, hold and flag). A threshold of 0 turns the rule off.
+func LoadAntiCheat() AntiCheatConfig {
+	return AntiCheatConfig{
+		MaxDelta:       loadAntiCheatRule("ANTICHEAT_MAX_DELTA", 500, models.AntiCheatHold),
+		MaxHourlyGain:  loadAntiCheatRule("ANTICHEAT_MAX_HOURLY_GAIN", 1000, models.AntiCheatHold),
+		Outlier:        loadAntiCheatRule("ANTICHEAT_OUTLIER_ZSCORE", 4, models.AntiCheatFlag),
+		OutlierSamples: parsePositiveInt("ANTICHEAT_OUTLIER_SAMPLES", 20),
+	}
+}
+
+func loadAntiCheatRule(key string, threshold float64, action string) AntiCheatRule {
+	rule := AntiCheatRule{Threshold: threshold, Action: action}
+	if value := os.Getenv(key); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			log.Printf("Warning: invalid %s=%q, using %v", key, value, rule.Threshold)
+		} else {
+			rule.Threshold = parsed
+		}
+	}
+	switch value := os.Getenv(key + "_ACTION"); value {
+	case "":
+	case models.AntiCheatReject, models.AntiCheatHold, models.AntiCheatFlag:
+		rule.Action = value
+	default:
+		log.Printf("Warning: invalid %s_ACTION=%q, using %s", key, value, rule.Action)
+	}
+	return rule
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"matiks/leaderboard/internal/auth"
+)
+
+// AuthConfig controls how requests are authenticated
+type AuthConfig struct {
+	// RequireRead makes the public read endpoints require the "read" scope
+	RequireRead bool
+	// JWKSFile enables JWT bearer tokens verified against the keys in this file
+	JWKSFile    string
+	JWTIssuer   string
+	JWTAudience string
+}
+
+// LoadAuth reads AUTH_REQUIRE_READ, JWT_JWKS_FILE, JWT_ISSUER and JWT_AUDIENCE
+func LoadAuth() AuthConfig {
+	cfg := AuthConfig{
+		JWKSFile:    os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:   os.Getenv("JWT_ISSUER"),
+		JWTAudience: os.Getenv("JWT_AUDIENCE"),
+	}
+	if value := os.Getenv("AUTH_REQUIRE_READ"); value != "" {
+		requireRead, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid AUTH_REQUIRE_READ=%q, leaving read endpoints public", value)
+		}
+		cfg.RequireRead = requireRead
+	}
+	return cfg
+}
+
+// JWTVerifier loads the configured JWKS file, returning nil when JWTs are disabled
+func (c AuthConfig) JWTVerifier() (*auth.JWTVerifier, error) {
+	if c.JWKSFile == "" {
+		return nil, nil
+	}
+	return auth.LoadJWKSFile(c.JWKSFile, c.JWTIssuer, c.JWTAudience)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CacheConfig controls the leaderboard page cache
+type CacheConfig struct {
+	// TTL bounds how long a page is served from cache; zero disables caching
+	TTL time.Duration
+	// MaxPage is the highest page number that is cached
+	MaxPage int
+}
+
+// LoadCache reads LEADERBOARD_CACHE_TTL (default 2s, "0s" disables) and
+// LEADERBOARD_CACHE_MAX_PAGE (default 5)
+func LoadCache() CacheConfig {
+	cfg := CacheConfig{
+		TTL:     2 * time.Second,
+		MaxPage: 5,
+	}
+	if value := os.Getenv("LEADERBOARD_CACHE_TTL"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl < 0 {
+			log.Printf("Warning: invalid LEADERBOARD_CACHE_TTL=%q, using %v", value, cfg.TTL)
+		} else {
+			cfg.TTL = ttl
+		}
+	}
+	if value := os.Getenv("LEADERBOARD_CACHE_MAX_PAGE"); value != "" {
+		maxPage, err := strconv.Atoi(value)
+		if err != nil || maxPage < 1 {
+			log.Printf("Warning: invalid LEADERBOARD_CACHE_MAX_PAGE=%q, using %d", value, cfg.MaxPage)
+		} else {
+			cfg.MaxPage = maxPage
+		}
+	}
+	return cfg
+}
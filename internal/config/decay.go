@@ -0,0 +1,80 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+)
+
+// DecayConfig controls the inactivity decay job
+type DecayConfig struct {
+	// Mode is models.DecayOff, DecayPoints or DecayHide
+	Mode string
+	// InactiveDays without a rating change before a user starts decaying
+	InactiveDays int
+	// Period is how often an inactive user loses Points
+	Period time.Duration
+	Points int
+	// Floor is the rating decay never goes below
+	Floor int
+	// BatchSize is how many users are examined per query
+	BatchSize int
+}
+
+// LoadDecay reads DECAY_MODE (default off), DECAY_INACTIVE_DAYS (default 30),
+// DECAY_PERIOD (default 168h), DECAY_POINTS (default 25), DECAY_FLOOR
+// (default 1200) and DECAY_BATCH_SIZE (default 500). The job's schedule is set
+// with JOB_SCHEDULE_RATING_DECAY.
+func LoadDecay() DecayConfig {
+	cfg := DecayConfig{
+		Mode:         models.DecayOff,
+		InactiveDays: 30,
+		Period:       7 * 24 * time.Hour,
+		Points:       25,
+		Floor:        1200,
+		BatchSize:    500,
+	}
+	switch value := os.Getenv("DECAY_MODE"); value {
+	case "":
+	case models.DecayOff, models.DecayPoints, models.DecayHide:
+		cfg.Mode = value
+	default:
+		log.Printf("Warning: invalid DECAY_MODE=%q, using %s", value, cfg.Mode)
+	}
+	cfg.InactiveDays = parsePositiveInt("DECAY_INACTIVE_DAYS", cfg.InactiveDays)
+	cfg.Points = parsePositiveInt("DECAY_POINTS", cfg.Points)
+	cfg.BatchSize = parsePositiveInt("DECAY_BATCH_SIZE", cfg.BatchSize)
+	if value := os.Getenv("DECAY_FLOOR"); value != "" {
+		floor, err := strconv.Atoi(value)
+		if err != nil || floor < models.MinRating || floor > models.MaxRating {
+			log.Printf("Warning: invalid DECAY_FLOOR=%q, using %d", value, cfg.Floor)
+		} else {
+			cfg.Floor = floor
+		}
+	}
+	if value := os.Getenv("DECAY_PERIOD"); value != "" {
+		period, err := time.ParseDuration(value)
+		if err != nil || period <= 0 {
+			log.Printf("Warning: invalid DECAY_PERIOD=%q, using %v", value, cfg.Period)
+		} else {
+			cfg.Period = period
+		}
+	}
+	return cfg
+}
+
+func parsePositiveInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Printf("Warning: invalid %s=%q, using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,15 @@
+package config
+
+// EventsConfig controls the domain event stream
+type EventsConfig struct {
+	// MaxLen is roughly how many events the stream keeps; older ones are
+	// trimmed as new ones are added
+	MaxLen int
+}
+
+// LoadEvents reads EVENTS_STREAM_MAX_LEN (default 100000)
+func LoadEvents() EventsConfig {
+	return EventsConfig{
+		MaxLen: parsePositiveInt("EVENTS_STREAM_MAX_LEN", 100000),
+	}
+}
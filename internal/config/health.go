@@ -0,0 +1,78 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// HealthConfig controls the readiness checks behind /readyz
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration
+	// QueueSaturation is the update queue fill ratio at which the instance
+	// reports not ready
+	QueueSaturation float64
+	// MaxDrift is how many users Redis and Postgres may differ by before the
+	// instance reports not ready; any drift below it only warns. Zero only warns.
+	MaxDrift int64
+	// DriftInterval is how long a drift measurement is reused, since it
+	// counts every user in Postgres
+	DriftInterval time.Duration
+	// RequireRedis makes an unavailable Redis fail readiness instead of
+	// degrading to Postgres-only
+	RequireRedis bool
+}
+
+// LoadHealth reads READYZ_CHECK_TIMEOUT (default 1s), READYZ_QUEUE_SATURATION
+// (default 0.9), READYZ_MAX_DRIFT (default 0), READYZ_DRIFT_INTERVAL
+// (default 30s) and READYZ_REQUIRE_REDIS (default false)
+func LoadHealth() HealthConfig {
+	cfg := HealthConfig{
+		CheckTimeout:    time.Second,
+		QueueSaturation: 0.9,
+		DriftInterval:   30 * time.Second,
+	}
+	if value := os.Getenv("READYZ_CHECK_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			log.Printf("Warning: invalid READYZ_CHECK_TIMEOUT=%q, using %v", value, cfg.CheckTimeout)
+		} else {
+			cfg.CheckTimeout = timeout
+		}
+	}
+	if value := os.Getenv("READYZ_QUEUE_SATURATION"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio <= 0 || ratio > 1 {
+			log.Printf("Warning: invalid READYZ_QUEUE_SATURATION=%q, using %v", value, cfg.QueueSaturation)
+		} else {
+			cfg.QueueSaturation = ratio
+		}
+	}
+	if value := os.Getenv("READYZ_MAX_DRIFT"); value != "" {
+		drift, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || drift < 0 {
+			log.Printf("Warning: invalid READYZ_MAX_DRIFT=%q, using %d", value, cfg.MaxDrift)
+		} else {
+			cfg.MaxDrift = drift
+		}
+	}
+	if value := os.Getenv("READYZ_DRIFT_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval < 0 {
+			log.Printf("Warning: invalid READYZ_DRIFT_INTERVAL=%q, using %v", value, cfg.DriftInterval)
+		} else {
+			cfg.DriftInterval = interval
+		}
+	}
+	if value := os.Getenv("READYZ_REQUIRE_REDIS"); value != "" {
+		require, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Warning: invalid READYZ_REQUIRE_REDIS=%q, using %v", value, cfg.RequireRedis)
+		} else {
+			cfg.RequireRedis = require
+		}
+	}
+	return cfg
+}
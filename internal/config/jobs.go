@@ -0,0 +1,75 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"matiks/leaderboard/internal/scheduler"
+)
+
+// JobOff as a schedule disables a job on this instance
+const JobOff = "off"
+
+// Scheduled job names
+const (
+	JobSimulateUpdates     = "simulate_updates"
+	JobReconcileRedis      = "reconcile_redis"
+	JobSnapshotLeaderboard = "snapshot_leaderboard"
+	JobPeakRankSweep       = "peak_rank_sweep"
+	JobRatingDecay         = "rating_decay"
+	JobPruneWebhooks       = "prune_webhook_deliveries"
+	JobAchievementSweep    = "achievement_sweep"
+)
+
+// defaultJobSchedules are used when no environment override is set
+var defaultJobSchedules = map[string]string{
+	JobSimulateUpdates:     "*/5 * * * *",
+	JobReconcileRedis:      "*/15 * * * *",
+	JobSnapshotLeaderboard: "@daily",
+	JobPeakRankSweep:       "*/10 * * * *",
+	JobRatingDecay:         "@hourly",
+	JobPruneWebhooks:       "@daily",
+	JobAchievementSweep:    "@hourly",
+}
+
+// Jobs holds the schedule of every job
+type Jobs struct {
+	Schedules map[string]string
+}
+
+// LoadJobs reads JOB_SCHEDULE_<JOB> (e.g. JOB_SCHEDULE_PEAK_RANK_SWEEP="*/30 * * * *")
+// for every job. A value of "off" disables the job; invalid expressions are
+// logged and ignored.
+func LoadJobs() Jobs {
+	j := Jobs{Schedules: make(map[string]string, len(defaultJobSchedules))}
+	for job, fallback := range defaultJobSchedules {
+		key := "JOB_SCHEDULE_" + strings.ToUpper(job)
+		value := strings.TrimSpace(os.Getenv(key))
+		switch {
+		case value == "":
+			value = fallback
+		case value == JobOff:
+		default:
+			if _, err := scheduler.ParseSchedule(value); err != nil {
+				log.Printf("Warning: invalid %s=%q (%v), using %q", key, value, err, fallback)
+				value = fallback
+			}
+		}
+		j.Schedules[job] = value
+	}
+	return j
+}
+
+// Schedule returns the schedule for the given job, or "off"
+func (j Jobs) Schedule(job string) string {
+	if schedule, ok := j.Schedules[job]; ok {
+		return schedule
+	}
+	return JobOff
+}
+
+// Enabled reports whether the given job runs on this instance
+func (j Jobs) Enabled(job string) bool {
+	return j.Schedule(job) != JobOff
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ProfileConfig controls GET /api/v1/users/:username
+type ProfileConfig struct {
+	// ComponentTimeout bounds each part of a profile (rank, peak, games,
+	// trend, neighbors); a part that misses it is left out of the response
+	ComponentTimeout time.Duration
+	// TrendDays is the window of the recent rating trend
+	TrendDays int
+	// Neighbors is how many players above and below the user are returned
+	Neighbors int
+}
+
+// LoadProfile reads PROFILE_COMPONENT_TIMEOUT (default 500ms),
+// PROFILE_TREND_DAYS (default 30) and PROFILE_NEIGHBORS (default 2)
+func LoadProfile() ProfileConfig {
+	cfg := ProfileConfig{
+		ComponentTimeout: 500 * time.Millisecond,
+		TrendDays:        30,
+		Neighbors:        2,
+	}
+	if value := os.Getenv("PROFILE_COMPONENT_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			log.Printf("Warning: invalid PROFILE_COMPONENT_TIMEOUT=%q, using %v", value, cfg.ComponentTimeout)
+		} else {
+			cfg.ComponentTimeout = timeout
+		}
+	}
+	if value := os.Getenv("PROFILE_TREND_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Printf("Warning: invalid PROFILE_TREND_DAYS=%q, using %d", value, cfg.TrendDays)
+		} else {
+			cfg.TrendDays = days
+		}
+	}
+	if value := os.Getenv("PROFILE_NEIGHBORS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > 25 {
+			log.Printf("Warning: invalid PROFILE_NEIGHBORS=%q, using %d", value, cfg.Neighbors)
+		} else {
+			cfg.Neighbors = n
+		}
+	}
+	return cfg
+}
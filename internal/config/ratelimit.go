@@ -0,0 +1,68 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/ratelimit"
+)
+
+// DefaultRateLimit applies to routes without an explicit rule
+var DefaultRateLimit = ratelimit.Rule{Limit: 60, Period: time.Minute}
+
+// defaultRouteRateLimits are used when no environment override is set.
+// Search does an ILIKE scan per request, so it gets the tightest budget.
+var defaultRouteRateLimits = map[string]ratelimit.Rule{
+	RouteLeaderboard:  {Limit: 120, Period: time.Minute},
+	RouteUserSearch:   {Limit: 30, Period: time.Minute},
+	RouteUserRank:     {Limit: 120, Period: time.Minute},
+	RouteSubmitRating: {Limit: 60, Period: time.Minute},
+}
+
+// RateLimits holds per-route rate limit rules
+type RateLimits struct {
+	Default ratelimit.Rule
+	Routes  map[string]ratelimit.Rule
+}
+
+// LoadRateLimits reads rate limits from the environment.
+// RATE_LIMIT sets the default and RATE_LIMIT_<ROUTE> (e.g. RATE_LIMIT_USER_SEARCH=10/1m)
+// overrides a single route. "off" disables limiting; invalid values are logged and ignored.
+func LoadRateLimits() RateLimits {
+	limits := RateLimits{
+		Default: parseRateLimit("RATE_LIMIT", DefaultRateLimit),
+		Routes:  make(map[string]ratelimit.Rule, len(routes)),
+	}
+	// Every route with a deadline can also be rate limited
+	for _, route := range routes {
+		fallback, ok := defaultRouteRateLimits[route]
+		if !ok {
+			fallback = limits.Default
+		}
+		limits.Routes[route] = parseRateLimit("RATE_LIMIT_"+strings.ToUpper(route), fallback)
+	}
+	return limits
+}
+
+// For returns the rule for the given route
+func (l RateLimits) For(route string) ratelimit.Rule {
+	if rule, ok := l.Routes[route]; ok {
+		return rule
+	}
+	return l.Default
+}
+
+func parseRateLimit(key string, fallback ratelimit.Rule) ratelimit.Rule {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	rule, err := ratelimit.ParseRule(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s=%q (%v), using %s", key, value, err, fallback)
+		return fallback
+	}
+	return rule
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -27,9 +30,50 @@ func ConnectRedis() (*redis.Client, error) {
 	}
 
 	Redis = redis.NewClient(opt)
+
+	// Emit a span per command; the hook picks up the global tracer provider
+	if err := redisotel.InstrumentTracing(Redis); err != nil {
+		return nil, fmt.Errorf("failed to enable redis tracing: %w", err)
+	}
+
 	return Redis, nil
 }
 
 func GetRedis() *redis.Client {
 	return Redis
 }
+
+// RedisHealthConfig controls the Redis circuit breaker and reconnection probe
+type RedisHealthConfig struct {
+	// FailureThreshold is how many consecutive failed commands open the circuit
+	FailureThreshold int
+	// ProbeInterval is how often Redis is pinged, both to detect outages and
+	// to notice when it is back
+	ProbeInterval time.Duration
+}
+
+// LoadRedisHealth reads REDIS_BREAKER_THRESHOLD (default 5) and
+// REDIS_PROBE_INTERVAL (default 2s)
+func LoadRedisHealth() RedisHealthConfig {
+	cfg := RedisHealthConfig{
+		FailureThreshold: 5,
+		ProbeInterval:    2 * time.Second,
+	}
+	if value := os.Getenv("REDIS_BREAKER_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 1 {
+			log.Printf("Warning: invalid REDIS_BREAKER_THRESHOLD=%q, using %d", value, cfg.FailureThreshold)
+		} else {
+			cfg.FailureThreshold = threshold
+		}
+	}
+	if value := os.Getenv("REDIS_PROBE_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Printf("Warning: invalid REDIS_PROBE_INTERVAL=%q, using %v", value, cfg.ProbeInterval)
+		} else {
+			cfg.ProbeInterval = interval
+		}
+	}
+	return cfg
+}
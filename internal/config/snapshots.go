@@ -0,0 +1,18 @@
+package config
+
+// SnapshotConfig controls the leaderboard snapshot job
+type SnapshotConfig struct {
+	// Size is how many of the top users each snapshot keeps
+	Size int
+	// RetentionDays is how long snapshots are kept
+	RetentionDays int
+}
+
+// LoadSnapshots reads SNAPSHOT_SIZE (default 1000) and SNAPSHOT_RETENTION_DAYS
+// (default 90). The job's schedule is set with JOB_SCHEDULE_SNAPSHOT_LEADERBOARD.
+func LoadSnapshots() SnapshotConfig {
+	return SnapshotConfig{
+		Size:          parsePositiveInt("SNAPSHOT_SIZE", 1000),
+		RetentionDays: parsePositiveInt("SNAPSHOT_RETENTION_DAYS", 90),
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultRequestTimeout applies to routes without an explicit override
+const DefaultRequestTimeout = 5 * time.Second
+
+// Route names used to look up per-route deadlines
+const (
+	RouteLeaderboard     = "leaderboard"
+	RouteUserSearch      = "user_search"
+	RouteUserRank        = "user_rank"
+	RouteUserProfile     = "user_profile"
+	RouteHallOfFame      = "hall_of_fame"
+	RouteEvents          = "events"
+	RouteAchievements    = "achievements"
+	RouteSubmitRating    = "submit_rating"
+	RouteRegisterUser    = "register_user"
+	RouteAdminSyncRedis  = "admin_sync_redis"
+	RouteAdminSimulation = "admin_simulate_updates"
+	RouteAdminAPIKeys    = "admin_api_keys"
+	RouteAdminImport     = "admin_import"
+	RouteAdminExport     = "admin_export"
+	RouteAdminUsers      = "admin_users"
+	RouteAdminDecay      = "admin_decay"
+	RouteAdminJobs       = "admin_jobs"
+	RouteAdminAudit      = "admin_audit"
+	RouteAdminRatings    = "admin_ratings"
+	RouteAdminReviews    = "admin_reviews"
+	RouteAdminWebhooks   = "admin_webhooks"
+)
+
+// routes lists every route that can be given its own deadline
+var routes = []string{
+	RouteLeaderboard, RouteUserSearch, RouteUserRank, RouteUserProfile, RouteHallOfFame,
+	RouteEvents, RouteAchievements, RouteSubmitRating, RouteRegisterUser,
+	RouteAdminSyncRedis, RouteAdminSimulation, RouteAdminAPIKeys, RouteAdminImport,
+	RouteAdminExport, RouteAdminUsers, RouteAdminDecay, RouteAdminJobs, RouteAdminAudit,
+	RouteAdminRatings, RouteAdminReviews, RouteAdminWebhooks,
+}
+
+// defaultRouteTimeouts are the long-running admin routes, which keep their
+// own deadline unless overridden. Every other route uses the default.
+var defaultRouteTimeouts = map[string]time.Duration{
+	RouteAdminSyncRedis: 60 * time.Second,
+	RouteAdminImport:    10 * time.Minute,
+	RouteAdminExport:    10 * time.Minute,
+	RouteAdminDecay:     5 * time.Minute,
+	RouteAdminRatings:   60 * time.Second,
+}
+
+// Timeouts holds per-route request deadlines
+type Timeouts struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// LoadTimeouts reads request deadlines from the environment.
+// REQUEST_TIMEOUT sets the default and REQUEST_TIMEOUT_<ROUTE> (e.g.
+// REQUEST_TIMEOUT_USER_SEARCH=1500ms) overrides a single route.
+// Values use Go duration syntax; invalid values are logged and ignored.
+func LoadTimeouts() Timeouts {
+	t := Timeouts{
+		Default: parseTimeout("REQUEST_TIMEOUT", DefaultRequestTimeout),
+		Routes:  make(map[string]time.Duration, len(defaultRouteTimeouts)),
+	}
+	for _, route := range routes {
+		key := "REQUEST_TIMEOUT_" + strings.ToUpper(route)
+		fallback, ok := defaultRouteTimeouts[route]
+		if !ok {
+			if os.Getenv(key) == "" {
+				continue
+			}
+			fallback = t.Default
+		}
+		t.Routes[route] = parseTimeout(key, fallback)
+	}
+	return t
+}
+
+// For returns the deadline for the given route
+func (t Timeouts) For(route string) time.Duration {
+	if d, ok := t.Routes[route]; ok {
+		return d
+	}
+	return t.Default
+}
+
+func parseTimeout(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: invalid %s=%q, using %v", key, value, fallback)
+		return fallback
+	}
+	return d
+}
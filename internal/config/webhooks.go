@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookConfig controls webhook delivery and the milestones that trigger it
+type WebhookConfig struct {
+	// Timeout is how long one delivery attempt may take
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it fails
+	MaxAttempts int
+	// BackoffBase is the wait before the first retry; each retry doubles it,
+	// up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// PollInterval is how often due deliveries are looked for when no new
+	// event has arrived
+	PollInterval time.Duration
+	// TopRanks are the top-N boundaries whose crossing is announced, ascending
+	TopRanks []int
+	// RetentionDays is how long the delivery log is kept
+	RetentionDays int
+}
+
+// LoadWebhooks reads WEBHOOK_TIMEOUT (default 5s), WEBHOOK_MAX_ATTEMPTS
+// (default 8), WEBHOOK_BACKOFF_BASE (default 10s), WEBHOOK_BACKOFF_MAX
+// (default 1h), WEBHOOK_POLL_INTERVAL (default 2s), WEBHOOK_TOP_RANKS
+// (comma-separated, default "10,100") and WEBHOOK_RETENTION_DAYS (default
+// 30). Old deliveries are pruned by the prune_webhook_deliveries job.
+func LoadWebhooks() WebhookConfig {
+	cfg := WebhookConfig{
+		Timeout:       parseTimeout("WEBHOOK_TIMEOUT", 5*time.Second),
+		MaxAttempts:   parsePositiveInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		BackoffBase:   parseTimeout("WEBHOOK_BACKOFF_BASE", 10*time.Second),
+		BackoffMax:    parseTimeout("WEBHOOK_BACKOFF_MAX", time.Hour),
+		PollInterval:  parseTimeout("WEBHOOK_POLL_INTERVAL", 2*time.Second),
+		TopRanks:      []int{10, 100},
+		RetentionDays: parsePositiveInt("WEBHOOK_RETENTION_DAYS", 30),
+	}
+	if value := os.Getenv("WEBHOOK_TOP_RANKS"); value != "" {
+		ranks, err := parseTopRanks(value)
+		if err != nil {
+			log.Printf("Warning: invalid WEBHOOK_TOP_RANKS=%q (%v), using %v", value, err, cfg.TopRanks)
+		} else {
+			cfg.TopRanks = ranks
+		}
+	}
+	return cfg
+}
+
+func parseTopRanks(value string) ([]int, error) {
+	var ranks []int
+	for _, part := range strings.Split(value, ",") {
+		rank, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if rank < 2 {
+			// Reaching #1 has its own event
+			return nil, errors.New("ranks must be at least 2")
+		}
+		ranks = append(ranks, rank)
+	}
+	slices.Sort(ranks)
+	return slices.Compact(ranks), nil
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type AchievementController struct {
+	achievementService *service.AchievementService
+}
+
+func NewAchievementController(achievementService *service.AchievementService) *AchievementController {
+	return &AchievementController{achievementService: achievementService}
+}
+
+func (c *AchievementController) Stats(ctx context.Context) (_ *models.AchievementStatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AchievementController.Stats")
+	defer func() { tracing.End(span, err) }()
+
+	return c.achievementService.Stats(ctx)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"context"
+	"io"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type AdminController struct {
+	syncService   *service.SyncService
+	importService *service.ImportService
+	exportService *service.ExportService
+	decayService  *service.DecayService
+}
+
+func NewAdminController(syncService *service.SyncService, importService *service.ImportService, exportService *service.ExportService, decayService *service.DecayService) *AdminController {
+	return &AdminController{syncService: syncService, importService: importService, exportService: exportService, decayService: decayService}
+}
+
+func (c *AdminController) SyncRedis(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AdminController.SyncRedis")
+	defer func() { tracing.End(span, err) }()
+
+	return c.syncService.SyncRedis(ctx)
+}
+
+func (c *AdminController) Import(ctx context.Context, r io.Reader, format string) (_ *models.ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "AdminController.Import")
+	defer func() { tracing.End(span, err) }()
+
+	return c.importService.Import(ctx, r, format)
+}
+
+func (c *AdminController) Export(ctx context.Context, w io.Writer, format string) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AdminController.Export")
+	defer func() { tracing.End(span, err) }()
+
+	return c.exportService.Export(ctx, w, format)
+}
+
+func (c *AdminController) RunDecay(ctx context.Context, dryRun bool) (_ *models.DecayReport, err error) {
+	ctx, span := tracing.Start(ctx, "AdminController.RunDecay")
+	defer func() { tracing.End(span, err) }()
+
+	return c.decayService.Run(ctx, dryRun)
+}
+
+func (c *AdminController) DecayRuns(ctx context.Context, limit int) (_ []models.DecayRun, err error) {
+	ctx, span := tracing.Start(ctx, "AdminController.DecayRuns")
+	defer func() { tracing.End(span, err) }()
+
+	return c.decayService.Runs(ctx, limit)
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type AuditController struct {
+	auditService *service.AuditService
+}
+
+func NewAuditController(auditService *service.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+func (c *AuditController) List(ctx context.Context, filter repository.AuditFilter, page, limit int) (_ *models.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditController.List")
+	defer func() { tracing.End(span, err) }()
+
+	return c.auditService.List(ctx, filter, page, limit)
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+)
+
+type AuthController struct {
+	authService *service.AuthService
+}
+
+func NewAuthController(authService *service.AuthService) *AuthController {
+	return &AuthController{authService: authService}
+}
+
+func (c *AuthController) CreateAPIKey(ctx context.Context, name, scopes string) (*models.CreatedAPIKey, error) {
+	return c.authService.CreateAPIKey(ctx, name, scopes)
+}
+
+func (c *AuthController) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return c.authService.ListAPIKeys(ctx)
+}
+
+func (c *AuthController) RevokeAPIKey(ctx context.Context, id int) error {
+	return c.authService.RevokeAPIKey(ctx, id)
+}
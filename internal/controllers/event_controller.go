@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type EventController struct {
+	eventService *service.EventService
+}
+
+func NewEventController(eventService *service.EventService) *EventController {
+	return &EventController{eventService: eventService}
+}
+
+func (c *EventController) Replay(ctx context.Context, since string, limit int) (_ *models.EventPage, err error) {
+	ctx, span := tracing.Start(ctx, "EventController.Replay")
+	defer func() { tracing.End(span, err) }()
+
+	return c.eventService.Replay(ctx, since, limit)
+}
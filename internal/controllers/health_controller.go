@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"context"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+)
+
+type HealthController struct {
+	healthService *service.HealthService
+}
+
+func NewHealthController(healthService *service.HealthService) *HealthController {
+	return &HealthController{healthService: healthService}
+}
+
+// Readiness is not traced: orchestrators poll it every few seconds
+func (c *HealthController) Readiness(ctx context.Context) *models.ReadinessResponse {
+	return c.healthService.Readiness(ctx)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/scheduler"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type JobController struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewJobController(scheduler *scheduler.Scheduler) *JobController {
+	return &JobController{scheduler: scheduler}
+}
+
+func (c *JobController) ListJobs(ctx context.Context) (_ []models.JobInfo, err error) {
+	ctx, span := tracing.Start(ctx, "JobController.ListJobs")
+	defer func() { tracing.End(span, err) }()
+
+	return c.scheduler.Jobs(ctx)
+}
+
+func (c *JobController) Runs(ctx context.Context, name string, limit int) (_ []models.JobRun, err error) {
+	ctx, span := tracing.Start(ctx, "JobController.Runs")
+	defer func() { tracing.End(span, err) }()
+
+	return c.scheduler.Runs(ctx, name, limit)
+}
+
+func (c *JobController) Trigger(ctx context.Context, name string) (_ *models.JobRun, err error) {
+	ctx, span := tracing.Start(ctx, "JobController.Trigger")
+	defer func() { tracing.End(span, err) }()
+
+	return c.scheduler.Trigger(ctx, name)
+}
+
+func (c *JobController) SetPaused(ctx context.Context, name string, paused bool) (_ *models.JobInfo, err error) {
+	ctx, span := tracing.Start(ctx, "JobController.SetPaused")
+	defer func() { tracing.End(span, err) }()
+
+	return c.scheduler.SetPaused(ctx, name, paused)
+}
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"context"
+	"matiks/leaderboard/internal/cache"
 	"matiks/leaderboard/internal/models"
 	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
 )
 
 type LeaderboardController struct {
@@ -13,16 +16,32 @@ func NewLeaderboardController(leaderboardService *service.LeaderboardService) *L
 	return &LeaderboardController{leaderboardService: leaderboardService}
 }
 
-func (c *LeaderboardController) GetLeaderboard(page, limit int) (*models.LeaderboardResponse, error) {
+func (c *LeaderboardController) GetLeaderboard(ctx context.Context, page, limit int) (_ *models.LeaderboardResponse, err error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardController.GetLeaderboard")
+	defer func() { tracing.End(span, err) }()
+
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 || limit > 100 {
 		limit = 50
 	}
-	response, err := c.leaderboardService.GetLeaderboard(page, limit)
+	response, err := c.leaderboardService.GetLeaderboard(ctx, page, limit)
 	if err != nil {
 		return nil, err
 	}
 	return response, nil
 }
+
+func (c *LeaderboardController) GetLeaderboardPage(ctx context.Context, page, limit int) (_ *cache.Page, err error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardController.GetLeaderboardPage")
+	defer func() { tracing.End(span, err) }()
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	return c.leaderboardService.GetLeaderboardPage(ctx, page, limit)
+}
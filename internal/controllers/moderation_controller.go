@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type ModerationController struct {
+	moderationService *service.ModerationService
+}
+
+func NewModerationController(moderationService *service.ModerationService) *ModerationController {
+	return &ModerationController{moderationService: moderationService}
+}
+
+func (c *ModerationController) SetRating(ctx context.Context, username string, rating int, reason string) (_ *models.RatingCorrection, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationController.SetRating")
+	defer func() { tracing.End(span, err) }()
+
+	return c.moderationService.SetRating(ctx, username, rating, reason)
+}
+
+func (c *ModerationController) AdjustRating(ctx context.Context, username string, delta int, reason string) (_ *models.RatingCorrection, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationController.AdjustRating")
+	defer func() { tracing.End(span, err) }()
+
+	return c.moderationService.AdjustRating(ctx, username, delta, reason)
+}
+
+func (c *ModerationController) Rollback(ctx context.Context, username string, since time.Time, matchID *int, reason string) (_ *models.RatingRollback, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationController.Rollback")
+	defer func() { tracing.End(span, err) }()
+
+	return c.moderationService.Rollback(ctx, username, since, matchID, reason)
+}
+
+func (c *ModerationController) BulkRollback(ctx context.Context, usernames []string, since time.Time, matchID *int, reason string) (_ *models.BulkRollbackReport, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationController.BulkRollback")
+	defer func() { tracing.End(span, err) }()
+
+	return c.moderationService.BulkRollback(ctx, usernames, since, matchID, reason)
+}
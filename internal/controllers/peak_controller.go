@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"context"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type PeakController struct {
+	peakService *service.PeakService
+}
+
+func NewPeakController(peakService *service.PeakService) *PeakController {
+	return &PeakController{peakService: peakService}
+}
+
+func (c *PeakController) HallOfFame(ctx context.Context, page, limit int) (_ *models.HallOfFameResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PeakController.HallOfFame")
+	defer func() { tracing.End(span, err) }()
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	return c.peakService.HallOfFame(ctx, page, limit)
+}
@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type ReviewController struct {
+	reviewService *service.ReviewService
+}
+
+func NewReviewController(reviewService *service.ReviewService) *ReviewController {
+	return &ReviewController{reviewService: reviewService}
+}
+
+func (c *ReviewController) List(ctx context.Context, status string, page, limit int) (_ *models.AnomalyListResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewController.List")
+	defer func() { tracing.End(span, err) }()
+
+	return c.reviewService.List(ctx, status, page, limit)
+}
+
+func (c *ReviewController) Approve(ctx context.Context, id int, note string) (_ *models.RatingAnomaly, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewController.Approve")
+	defer func() { tracing.End(span, err) }()
+
+	return c.reviewService.Approve(ctx, id, note)
+}
+
+func (c *ReviewController) Reject(ctx context.Context, id int, note string) (_ *models.RatingAnomaly, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewController.Reject")
+	defer func() { tracing.End(span, err) }()
+
+	return c.reviewService.Reject(ctx, id, note)
+}
@@ -1,6 +1,10 @@
 package controllers
 
-import "matiks/leaderboard/internal/service"
+import (
+	"context"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
 
 type UpdateController struct {
 	updateService *service.UpdateService
@@ -10,6 +14,16 @@ func NewUpdateController(updateService *service.UpdateService) *UpdateController
 	return &UpdateController{updateService: updateService}
 }
 
-func (c *UpdateController) SimulateUpdates(count int) error {
-	return c.updateService.SimulateRandomUpdates(count)
+func (c *UpdateController) SimulateUpdates(ctx context.Context, count int) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateController.SimulateUpdates")
+	defer func() { tracing.End(span, err) }()
+
+	return c.updateService.SimulateRandomUpdates(ctx, count)
+}
+
+func (c *UpdateController) SubmitRating(ctx context.Context, username string, rating int) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateController.SubmitRating")
+	defer func() { tracing.End(span, err) }()
+
+	return c.updateService.SubmitRating(ctx, username, rating)
 }
@@ -1,9 +1,11 @@
 package controllers
 
 import (
-	"errors"
+	"context"
+	"matiks/leaderboard/internal/apperrors"
 	"matiks/leaderboard/internal/models"
 	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
 )
 
 type UserController struct {
@@ -14,30 +16,98 @@ func NewUserController(userService *service.UserService) *UserController {
 	return &UserController{userService: userService}
 }
 
-func (c *UserController) SearchUsers(query string, page, limit int) (*models.UserSearchResponse, error) {
+func (c *UserController) SearchUsers(ctx context.Context, query string, page, limit int) (_ *models.UserSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.SearchUsers")
+	defer func() { tracing.End(span, err) }()
+
 	if query == "" {
-		return nil, errors.New("query is required")
+		return nil, apperrors.Validation("query is required")
 	}
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 || limit > 100 {
-		return nil, errors.New("limit must be between 1 and 100")
+		return nil, apperrors.Validation("limit must be between 1 and 100")
 	}
-	response, err := c.userService.SearchUsers(query, page, limit)
+	response, err := c.userService.SearchUsers(ctx, query, page, limit)
 	if err != nil {
 		return nil, err
 	}
 	return response, nil
 }
 
-func (c *UserController) GetUserRank(username string) (*models.UserRankResponse, error) {
+func (c *UserController) GetUserRank(ctx context.Context, username string) (_ *models.UserRankResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.GetUserRank")
+	defer func() { tracing.End(span, err) }()
+
 	if username == "" {
-		return nil, errors.New("username is required")
+		return nil, apperrors.Validation("username is required")
 	}
-	response, err := c.userService.GetUserRank(username)
+	response, err := c.userService.GetUserRank(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 	return response, nil
 }
+
+func (c *UserController) GetProfile(ctx context.Context, username string) (_ *models.UserProfile, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.GetProfile")
+	defer func() { tracing.End(span, err) }()
+
+	if username == "" {
+		return nil, apperrors.Validation("username is required")
+	}
+	return c.userService.GetProfile(ctx, username)
+}
+
+func (c *UserController) GetAchievements(ctx context.Context, username string) (_ *models.UserAchievementsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.GetAchievements")
+	defer func() { tracing.End(span, err) }()
+
+	if username == "" {
+		return nil, apperrors.Validation("username is required")
+	}
+	return c.userService.GetAchievements(ctx, username)
+}
+
+func (c *UserController) Register(ctx context.Context, username string, rating *int) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.Register")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.Register(ctx, username, rating)
+}
+
+func (c *UserController) GetUser(ctx context.Context, username string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.GetUser")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.GetUser(ctx, username)
+}
+
+func (c *UserController) Rename(ctx context.Context, username, newUsername string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.Rename")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.Rename(ctx, username, newUsername)
+}
+
+func (c *UserController) Delete(ctx context.Context, username string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserController.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.Delete(ctx, username)
+}
+
+func (c *UserController) SetBanned(ctx context.Context, username string, banned bool) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.SetBanned")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.SetBanned(ctx, username, banned)
+}
+
+func (c *UserController) SetHidden(ctx context.Context, username string, hidden bool) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserController.SetHidden")
+	defer func() { tracing.End(span, err) }()
+
+	return c.userService.SetHidden(ctx, username, hidden)
+}
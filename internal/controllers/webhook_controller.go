@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/service"
+	"matiks/leaderboard/internal/tracing"
+)
+
+type WebhookController struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+func (c *WebhookController) Create(ctx context.Context, url string, eventTypes []string, description string) (_ *models.CreatedWebhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookController.Create")
+	defer func() { tracing.End(span, err) }()
+
+	return c.webhookService.Create(ctx, url, eventTypes, description)
+}
+
+func (c *WebhookController) List(ctx context.Context) (_ []models.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookController.List")
+	defer func() { tracing.End(span, err) }()
+
+	return c.webhookService.List(ctx)
+}
+
+func (c *WebhookController) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookController.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return c.webhookService.Delete(ctx, id)
+}
+
+func (c *WebhookController) Deliveries(ctx context.Context, id int, status string, limit int) (_ []models.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookController.Deliveries")
+	defer func() { tracing.End(span, err) }()
+
+	return c.webhookService.Deliveries(ctx, id, status, limit)
+}
+
+func (c *WebhookController) Test(ctx context.Context, id int) (_ *models.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookController.Test")
+	defer func() { tracing.End(span, err) }()
+
+	return c.webhookService.Test(ctx, id)
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Handler processes one event. Returning nil acknowledges it; an error
+// leaves it pending, to be delivered again once it has been idle for
+// ConsumerOptions.RetryAfter.
+type Handler func(ctx context.Context, event models.StreamEvent) error
+
+// ConsumerOptions tune a Consumer; zero fields take the defaults
+type ConsumerOptions struct {
+	// Count is how many events are read at a time (default 100)
+	Count int64
+	// Block is how long a read waits for new events (default 5s)
+	Block time.Duration
+	// RetryAfter is how long an event stays pending, unacknowledged, before
+	// it is claimed and delivered again, to this or another consumer in the
+	// group (default 1m)
+	RetryAfter time.Duration
+}
+
+// Consumer reads the event stream as one member of a consumer group. Each
+// event goes to one consumer of the group and stays pending until its
+// handler succeeds, so delivery is at least once: handlers should be
+// idempotent, keyed on the event ID. Reclaiming pending events needs Redis
+// 6.2.
+type Consumer struct {
+	client  redis.UniversalClient
+	group   string
+	name    string
+	options ConsumerOptions
+}
+
+// NewConsumer creates a consumer called name in group. Names must be unique
+// within the group and stable across restarts, so a restarted consumer
+// picks up its own pending events first.
+func NewConsumer(client redis.UniversalClient, group, name string, options ConsumerOptions) *Consumer {
+	if options.Count <= 0 {
+		options.Count = 100
+	}
+	if options.Block <= 0 {
+		options.Block = 5 * time.Second
+	}
+	if options.RetryAfter <= 0 {
+		options.RetryAfter = time.Minute
+	}
+	return &Consumer{client: client, group: group, name: name, options: options}
+}
+
+// EnsureGroup creates the consumer group if it does not exist yet. start is
+// where a new group begins reading: "0" for every retained event, "$" for
+// events added from now on.
+func (c *Consumer) EnsureGroup(ctx context.Context, start string) error {
+	err := c.client.XGroupCreateMkStream(ctx, Stream, c.group, start).Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// Run hands events to handler until ctx is done. It first redelivers the
+// events this consumer left pending, then reads new ones, claiming events
+// other consumers left pending for longer than RetryAfter along the way.
+func (c *Consumer) Run(ctx context.Context, handler Handler) error {
+	// Pending events from before a restart, one batch after another. Events
+	// that fail again stay pending for claim to retry later.
+	for start := "0"; ; {
+		last, err := c.read(ctx, start, handler)
+		if err != nil {
+			return err
+		}
+		if last == "" {
+			break
+		}
+		start = last
+	}
+
+	lastClaim := time.Now()
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= c.options.RetryAfter {
+			if err := c.claim(ctx, handler); err != nil {
+				return err
+			}
+			lastClaim = time.Now()
+		}
+		if _, err := c.read(ctx, ">", handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read reads from the group after id, ">" for new events or an entry ID for
+// this consumer's pending ones, and returns the ID of the last entry it
+// read, or "" if there were none.
+func (c *Consumer) read(ctx context.Context, id string, handler Handler) (string, error) {
+	streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.group,
+		Consumer: c.name,
+		Streams:  []string{Stream, id},
+		Count:    c.options.Count,
+		Block:    c.options.Block,
+	}).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return "", nil
+	case ctx.Err() != nil:
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+
+	last := ""
+	for _, stream := range streams {
+		for _, msg := range stream.Messages {
+			c.handle(ctx, msg, handler)
+			last = msg.ID
+		}
+	}
+	return last, nil
+}
+
+// claim takes over events pending for longer than RetryAfter and handles them
+func (c *Consumer) claim(ctx context.Context, handler Handler) error {
+	start := "0-0"
+	for {
+		msgs, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   Stream,
+			Group:    c.group,
+			Consumer: c.name,
+			MinIdle:  c.options.RetryAfter,
+			Start:    start,
+			Count:    c.options.Count,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		for _, msg := range msgs {
+			c.handle(ctx, msg, handler)
+		}
+		if next == "0-0" {
+			return nil
+		}
+		start = next
+	}
+}
+
+// handle runs handler on one entry and acknowledges it on success. Entries
+// that cannot be decoded are acknowledged and skipped, or they would be
+// redelivered forever.
+func (c *Consumer) handle(ctx context.Context, msg redis.XMessage, handler Handler) {
+	event, err := Decode(msg)
+	if err != nil {
+		log.Printf("Skipping event: %v", err)
+	} else if err := handler(ctx, event); err != nil {
+		log.Printf("Event %s failed, leaving it pending: %v", msg.ID, err)
+		return
+	}
+	if err := c.client.XAck(ctx, Stream, c.group, msg.ID).Err(); err != nil {
+		log.Printf("Failed to acknowledge event %s: %v", msg.ID, err)
+	}
+}
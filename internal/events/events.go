@@ -0,0 +1,109 @@
+// Package events defines the domain event stream: a Redis Stream to which
+// the update pipeline appends an entry for every applied rating change.
+// Downstream services read it through a consumer group with Consumer, or
+// catch up over HTTP with GET /api/v1/events.
+package events
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stream is the key of the event stream
+const Stream = "leaderboard:events"
+
+// Fields of a stream entry; data holds the payload as JSON
+const (
+	fieldType    = "type"
+	fieldVersion = "version"
+	fieldData    = "data"
+)
+
+// ErrUnsupportedVersion is returned for an event whose payload version this
+// build does not understand
+var ErrUnsupportedVersion = errors.New("unsupported event version")
+
+// Values returns the fields of a stream entry for an event
+func Values(eventType string, version int, data interface{}) ([]interface{}, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{fieldType, eventType, fieldVersion, version, fieldData, string(payload)}, nil
+}
+
+// Decode turns a stream entry into an event
+func Decode(msg redis.XMessage) (models.StreamEvent, error) {
+	eventType, _ := msg.Values[fieldType].(string)
+	versionStr, _ := msg.Values[fieldVersion].(string)
+	data, _ := msg.Values[fieldData].(string)
+	version, err := strconv.Atoi(versionStr)
+	if eventType == "" || err != nil || !json.Valid([]byte(data)) {
+		return models.StreamEvent{}, fmt.Errorf("malformed event %s", msg.ID)
+	}
+	return models.StreamEvent{ID: msg.ID, Type: eventType, Version: version, Data: json.RawMessage(data)}, nil
+}
+
+// RatingChanged decodes the payload of a rating.changed event
+func RatingChanged(event models.StreamEvent) (models.RatingChanged, error) {
+	var change models.RatingChanged
+	if event.Type != models.EventRatingChanged {
+		return change, fmt.Errorf("event %s is %s, not %s", event.ID, event.Type, models.EventRatingChanged)
+	}
+	if event.Version != models.EventRatingChangedVersion {
+		return change, fmt.Errorf("%w: %s v%d", ErrUnsupportedVersion, event.Type, event.Version)
+	}
+	err := json.Unmarshal(event.Data, &change)
+	return change, err
+}
+
+// ParseID splits a stream ID, "<ms>-<seq>" or just "<ms>", into its parts
+func ParseID(id string) (ms, seq uint64, err error) {
+	msStr, seqStr, hasSeq := strings.Cut(id, "-")
+	if ms, err = strconv.ParseUint(msStr, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid stream ID %q", id)
+	}
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqStr, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid stream ID %q", id)
+		}
+	}
+	return ms, seq, nil
+}
+
+// After returns the ID right after id, so a range starting there leaves id
+// out. Exclusive ranges would need Redis 6.2.
+func After(id string) (string, error) {
+	ms, seq, err := ParseID(id)
+	if err != nil {
+		return "", err
+	}
+	if seq == ^uint64(0) {
+		return fmt.Sprintf("%d-0", ms+1), nil
+	}
+	return fmt.Sprintf("%d-%d", ms, seq+1), nil
+}
+
+// IDAt returns the first ID an event added at t can have
+func IDAt(t time.Time) string {
+	return fmt.Sprintf("%d-0", t.UnixMilli())
+}
+
+// Compare orders two valid stream IDs like strings.Compare
+func Compare(a, b string) int {
+	aMs, aSeq, _ := ParseID(a)
+	bMs, bSeq, _ := ParseID(b)
+	if c := cmp.Compare(aMs, bMs); c != 0 {
+		return c
+	}
+	return cmp.Compare(aSeq, bSeq)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AchievementHandler struct {
+	controller *controllers.AchievementController
+}
+
+func NewAchievementHandler(controller *controllers.AchievementController) *AchievementHandler {
+	return &AchievementHandler{controller: controller}
+}
+
+// Stats handles GET /api/v1/achievements
+func (h *AchievementHandler) Stats(c *gin.Context) {
+	response, err := h.controller.Stats(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+	"matiks/leaderboard/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	controller *controllers.AdminController
+}
+
+func NewAdminHandler(controller *controllers.AdminController) *AdminHandler {
+	return &AdminHandler{controller: controller}
+}
+
+// SyncRedis handles POST /api/v1/admin/sync-redis
+func (h *AdminHandler) SyncRedis(c *gin.Context) {
+	count, err := h.controller.SyncRedis(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Redis synced successfully",
+		"count":   count,
+	})
+}
+
+// Import handles POST /api/v1/admin/import?format=csv|jsonl with the file as
+// the request body
+func (h *AdminHandler) Import(c *gin.Context) {
+	format := c.DefaultQuery("format", service.FormatCSV)
+	report, err := h.controller.Import(c.Request.Context(), c.Request.Body, format)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Export handles GET /api/v1/admin/export?format=csv|jsonl, streaming the
+// whole leaderboard with ranks
+func (h *AdminHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", service.FormatCSV)
+	contentType, err := service.ExportContentType(format)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="leaderboard.%s"`, format))
+	if _, err := h.controller.Export(c.Request.Context(), c.Writer, format); err != nil {
+		if c.Writer.Written() {
+			// Too late for an error response; the client sees a truncated file
+			log.Printf("Export failed mid-stream: %v", err)
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		c.Error(err)
+	}
+}
+
+// RunDecay handles POST /api/v1/admin/decay/run?dry_run=true|false
+func (h *AdminHandler) RunDecay(c *gin.Context) {
+	dryRunStr := c.DefaultQuery("dry_run", "false")
+	dryRun, err := strconv.ParseBool(dryRunStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid dry_run parameter").WithDetail("dry_run", dryRunStr))
+		return
+	}
+
+	report, err := h.controller.RunDecay(c.Request.Context(), dryRun)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DecayRuns handles GET /api/v1/admin/decay/runs?limit=20
+func (h *AdminHandler) DecayRuns(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	runs, err := h.controller.DecayRuns(c.Request.Context(), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
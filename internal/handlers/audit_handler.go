@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+	"matiks/leaderboard/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	controller *controllers.AuditController
+}
+
+func NewAuditHandler(controller *controllers.AuditController) *AuditHandler {
+	return &AuditHandler{controller: controller}
+}
+
+// List handles GET /api/v1/admin/audit?actor=&action=&target_type=&target=&outcome=&request_id=&since=&until=&page=1&limit=50
+func (h *AuditHandler) List(c *gin.Context) {
+	pageStr := c.DefaultQuery("page", "1")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid page parameter").WithDetail("page", pageStr))
+		return
+	}
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	filter := repository.AuditFilter{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		Target:     c.Query("target"),
+		Outcome:    c.Query("outcome"),
+		RequestID:  c.Query("request_id"),
+	}
+	if filter.Since, err = timeQuery(c, "since"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.Until, err = timeQuery(c, "until"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	response, err := h.controller.List(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// timeQuery parses an optional RFC 3339 query parameter
+func timeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperrors.Validation("%s must be an RFC 3339 time", name).WithDetail(name, value)
+	}
+	return &t, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	controller *controllers.AuthController
+}
+
+func NewAuthHandler(controller *controllers.AuthController) *AuthHandler {
+	return &AuthHandler{controller: controller}
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateAPIKey handles POST /api/v1/admin/api-keys
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("invalid request body").Wrap(err))
+		return
+	}
+
+	key, err := h.controller.CreateAPIKey(c.Request.Context(), req.Name, strings.Join(req.Scopes, ","))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys handles GET /api/v1/admin/api-keys
+func (h *AuthHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.controller.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/admin/api-keys/:id
+func (h *AuthHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperrors.Validation("invalid api key id").WithDetail("id", c.Param("id")))
+		return
+	}
+
+	if err := h.controller.RevokeAPIKey(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
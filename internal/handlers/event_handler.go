@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventHandler struct {
+	controller *controllers.EventController
+}
+
+func NewEventHandler(controller *controllers.EventController) *EventHandler {
+	return &EventHandler{controller: controller}
+}
+
+// Replay handles GET /api/v1/events?since=1760796347000-0&limit=100
+func (h *EventHandler) Replay(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "100")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	page, err := h.controller.Replay(c.Request.Context(), c.Query("since"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
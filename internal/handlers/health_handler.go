@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"matiks/leaderboard/internal/controllers"
+	"matiks/leaderboard/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	controller *controllers.HealthController
+	startedAt  time.Time
+}
+
+func NewHealthHandler(controller *controllers.HealthController) *HealthHandler {
+	return &HealthHandler{controller: controller, startedAt: time.Now()}
+}
+
+// Livez handles GET /livez. It checks no dependencies: a failing database
+// should take the instance out of rotation, not get it restarted.
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "alive",
+		"uptime_seconds": int(time.Since(h.startedAt).Seconds()),
+	})
+}
+
+// Readyz handles GET /readyz, answering 503 when the instance should not take traffic
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.controller.Readiness(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == service.StatusNotReady {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	controller *controllers.JobController
+}
+
+func NewJobHandler(controller *controllers.JobController) *JobHandler {
+	return &JobHandler{controller: controller}
+}
+
+// ListJobs handles GET /api/v1/admin/jobs
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.controller.ListJobs(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// Runs handles GET /api/v1/admin/jobs/:name/runs?limit=20
+func (h *JobHandler) Runs(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	runs, err := h.controller.Runs(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// Trigger handles POST /api/v1/admin/jobs/:name/run. The run continues in
+// the background; its outcome shows up in the job's runs.
+func (h *JobHandler) Trigger(c *gin.Context) {
+	run, err := h.controller.Trigger(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// Pause handles POST /api/v1/admin/jobs/:name/pause
+func (h *JobHandler) Pause(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// Resume handles POST /api/v1/admin/jobs/:name/resume
+func (h *JobHandler) Resume(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+func (h *JobHandler) setPaused(c *gin.Context, paused bool) {
+	job, err := h.controller.SetPaused(c.Request.Context(), c.Param("name"), paused)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
+	"matiks/leaderboard/internal/apperrors"
 	"matiks/leaderboard/internal/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -25,23 +27,43 @@ func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
 
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page parameter"})
+		c.Error(apperrors.Validation("invalid page parameter").WithDetail("page", pageStr))
 		return
 	}
 
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 1 || limit > 100 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
 		return
 	}
 
 	// 2. Call controller
-	response, err := h.controller.GetLeaderboard(page, limit)
+	response, err := h.controller.GetLeaderboardPage(c.Request.Context(), page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaderboard"})
+		c.Error(err)
 		return
 	}
 
-	// 3. Return response
-	c.JSON(http.StatusOK, response)
+	// 3. Return response, or 304 if the client already has this version
+	c.Header("ETag", response.ETag)
+	c.Header("Cache-Control", "no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), response.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response.Body)
+}
+
+// etagMatches implements the If-None-Match comparison (weak comparison, per RFC 9110)
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
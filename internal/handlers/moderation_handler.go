@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	controller *controllers.ModerationController
+}
+
+func NewModerationHandler(controller *controllers.ModerationController) *ModerationHandler {
+	return &ModerationHandler{controller: controller}
+}
+
+type setRatingRequest struct {
+	Rating *int   `json:"rating" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// SetRating handles PUT /api/v1/admin/users/:username/rating
+func (h *ModerationHandler) SetRating(c *gin.Context) {
+	var req setRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain a numeric rating and a reason").Wrap(err))
+		return
+	}
+
+	correction, err := h.controller.SetRating(c.Request.Context(), c.Param("username"), *req.Rating, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, correction)
+}
+
+type adjustRatingRequest struct {
+	Delta  *int   `json:"delta" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// AdjustRating handles POST /api/v1/admin/users/:username/rating/adjust
+func (h *ModerationHandler) AdjustRating(c *gin.Context) {
+	var req adjustRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain a numeric delta and a reason").Wrap(err))
+		return
+	}
+
+	correction, err := h.controller.AdjustRating(c.Request.Context(), c.Param("username"), *req.Delta, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, correction)
+}
+
+type rollbackRequest struct {
+	Since   time.Time `json:"since"`
+	MatchID *int      `json:"match_id"`
+	Reason  string    `json:"reason"`
+}
+
+// Rollback handles POST /api/v1/admin/users/:username/rating/rollback
+func (h *ModerationHandler) Rollback(c *gin.Context) {
+	var req rollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain an RFC 3339 since time, a reason and optionally a match_id").Wrap(err))
+		return
+	}
+
+	result, err := h.controller.Rollback(c.Request.Context(), c.Param("username"), req.Since, req.MatchID, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type bulkRollbackRequest struct {
+	Usernames []string  `json:"usernames"`
+	Since     time.Time `json:"since"`
+	MatchID   *int      `json:"match_id"`
+	Reason    string    `json:"reason"`
+}
+
+// BulkRollback handles POST /api/v1/admin/ratings/rollback
+func (h *ModerationHandler) BulkRollback(c *gin.Context) {
+	var req bulkRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain usernames, an RFC 3339 since time, a reason and optionally a match_id").Wrap(err))
+		return
+	}
+
+	report, err := h.controller.BulkRollback(c.Request.Context(), req.Usernames, req.Since, req.MatchID, req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
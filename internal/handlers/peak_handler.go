@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PeakHandler struct {
+	controller *controllers.PeakController
+}
+
+func NewPeakHandler(controller *controllers.PeakController) *PeakHandler {
+	return &PeakHandler{controller: controller}
+}
+
+// HallOfFame handles GET /api/v1/hall-of-fame
+func (h *PeakHandler) HallOfFame(c *gin.Context) {
+	// 1. Extract query parameters
+	pageStr := c.DefaultQuery("page", "1")
+	limitStr := c.DefaultQuery("limit", "50")
+
+	page, err := strconv.Atoi(pageStr)
+	if err != nil || page < 1 {
+		c.Error(apperrors.Validation("invalid page parameter").WithDetail("page", pageStr))
+		return
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	// 2. Call controller
+	response, err := h.controller.HallOfFame(c.Request.Context(), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 3. Return response
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+	"matiks/leaderboard/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	controller *controllers.ReviewController
+}
+
+func NewReviewHandler(controller *controllers.ReviewController) *ReviewHandler {
+	return &ReviewHandler{controller: controller}
+}
+
+// List handles GET /api/v1/admin/reviews?status=pending&page=1&limit=50
+func (h *ReviewHandler) List(c *gin.Context) {
+	pageStr := c.DefaultQuery("page", "1")
+	page, err := strconv.Atoi(pageStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid page parameter").WithDetail("page", pageStr))
+		return
+	}
+	limitStr := c.DefaultQuery("limit", "50")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	response, err := h.controller.List(c.Request.Context(), c.DefaultQuery("status", models.AnomalyPending), page, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type reviewRequest struct {
+	Note string `json:"note"`
+}
+
+// Approve handles POST /api/v1/admin/reviews/:id/approve with an optional
+// { "note": "..." } body
+func (h *ReviewHandler) Approve(c *gin.Context) {
+	id, note, ok := h.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	anomaly, err := h.controller.Approve(c.Request.Context(), id, note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+// Reject handles POST /api/v1/admin/reviews/:id/reject with an optional
+// { "note": "..." } body
+func (h *ReviewHandler) Reject(c *gin.Context) {
+	id, note, ok := h.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	anomaly, err := h.controller.Reject(c.Request.Context(), id, note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, anomaly)
+}
+
+func (h *ReviewHandler) reviewParams(c *gin.Context) (int, string, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperrors.Validation("invalid anomaly id").WithDetail("id", c.Param("id")))
+		return 0, "", false
+	}
+	var req reviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apperrors.Validation("request body must be empty or contain a note").Wrap(err))
+		return 0, "", false
+	}
+	return id, req.Note, true
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"matiks/leaderboard/internal/apperrors"
 	"matiks/leaderboard/internal/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -22,17 +23,13 @@ func (h *UpdateHandler) SimulateUpdates(c *gin.Context) {
 	countStr := c.DefaultQuery("count", "10")
 	count, err := strconv.Atoi(countStr)
 	if err != nil || count < 1 || count > 100 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "count must be between 1 and 100",
-		})
+		c.Error(apperrors.Validation("count must be between 1 and 100").WithDetail("count", countStr))
 		return
 	}
 
 	// Queue updates (non-blocking)
-	if err := h.controller.SimulateUpdates(count); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+	if err := h.controller.SimulateUpdates(c.Request.Context(), count); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -41,3 +38,30 @@ func (h *UpdateHandler) SimulateUpdates(c *gin.Context) {
 		"count":   count,
 	})
 }
+
+type submitRatingRequest struct {
+	Rating int `json:"rating" binding:"required"`
+}
+
+// SubmitRating handles POST /api/v1/users/:username/rating
+func (h *UpdateHandler) SubmitRating(c *gin.Context) {
+	username := c.Param("username")
+
+	var req submitRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain a numeric rating").Wrap(err))
+		return
+	}
+
+	// Queued; applied asynchronously by the update workers
+	if err := h.controller.SubmitRating(c.Request.Context(), username, req.Rating); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  "Rating update queued",
+		"username": username,
+		"rating":   req.Rating,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"matiks/leaderboard/internal/apperrors"
 	"matiks/leaderboard/internal/controllers"
 
 	"github.com/gin-gonic/gin"
@@ -22,7 +23,7 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 	// 1. Extract query parameters
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		c.Error(apperrors.Validation("query parameter 'q' is required"))
 		return
 	}
 
@@ -39,9 +40,9 @@ func (h *UserHandler) SearchUsers(c *gin.Context) {
 	}
 
 	// 2. Call controller
-	response, err := h.controller.SearchUsers(query, page, limit)
+	response, err := h.controller.SearchUsers(c.Request.Context(), query, page, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		c.Error(err)
 		return
 	}
 
@@ -54,21 +55,140 @@ func (h *UserHandler) GetUserRank(c *gin.Context) {
 	// 1. Extract path parameter
 	username := c.Param("username")
 	if username == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is required"})
+		c.Error(apperrors.Validation("username is required"))
 		return
 	}
 
 	// 2. Call controller
-	response, err := h.controller.GetUserRank(username)
+	response, err := h.controller.GetUserRank(c.Request.Context(), username)
 	if err != nil {
-		if err.Error() == "user not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user rank"})
+		c.Error(err)
 		return
 	}
 
 	// 3. Return response
 	c.JSON(http.StatusOK, response)
 }
+
+// GetProfile handles GET /api/v1/users/:username
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	// 1. Extract path parameter
+	username := c.Param("username")
+	if username == "" {
+		c.Error(apperrors.Validation("username is required"))
+		return
+	}
+
+	// 2. Call controller
+	response, err := h.controller.GetProfile(c.Request.Context(), username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 3. Return response
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAchievements handles GET /api/v1/users/:username/achievements
+func (h *UserHandler) GetAchievements(c *gin.Context) {
+	username := c.Param("username")
+	if username == "" {
+		c.Error(apperrors.Validation("username is required"))
+		return
+	}
+
+	response, err := h.controller.GetAchievements(c.Request.Context(), username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type registerUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Rating   *int   `json:"rating"`
+}
+
+// Register handles POST /api/v1/users
+func (h *UserHandler) Register(c *gin.Context) {
+	var req registerUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain a username and optionally a numeric rating").Wrap(err))
+		return
+	}
+
+	user, err := h.controller.Register(c.Request.Context(), req.Username, req.Rating)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// GetUser handles GET /api/v1/admin/users/:username
+func (h *UserHandler) GetUser(c *gin.Context) {
+	user, err := h.controller.GetUser(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+type renameUserRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// Rename handles PATCH /api/v1/admin/users/:username
+func (h *UserHandler) Rename(c *gin.Context) {
+	var req renameUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain the new username").Wrap(err))
+		return
+	}
+
+	user, err := h.controller.Rename(c.Request.Context(), c.Param("username"), req.Username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Delete handles DELETE /api/v1/admin/users/:username
+func (h *UserHandler) Delete(c *gin.Context) {
+	if err := h.controller.Delete(c.Request.Context(), c.Param("username")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Ban handles PUT (ban) and DELETE (unban) /api/v1/admin/users/:username/ban
+func (h *UserHandler) Ban(c *gin.Context) {
+	user, err := h.controller.SetBanned(c.Request.Context(), c.Param("username"), c.Request.Method == http.MethodPut)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Hide handles PUT (hide) and DELETE (show) /api/v1/admin/users/:username/hide
+func (h *UserHandler) Hide(c *gin.Context) {
+	user, err := h.controller.SetHidden(c.Request.Context(), c.Param("username"), c.Request.Method == http.MethodPut)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	controller *controllers.WebhookController
+}
+
+func NewWebhookHandler(controller *controllers.WebhookController) *WebhookHandler {
+	return &WebhookHandler{controller: controller}
+}
+
+type createWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+}
+
+// Create handles POST /api/v1/admin/webhooks. The response holds the signing
+// secret, which is not shown again.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperrors.Validation("request body must contain a url and event_types").Wrap(err))
+		return
+	}
+
+	hook, err := h.controller.Create(c.Request.Context(), req.URL, req.EventTypes, req.Description)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// List handles GET /api/v1/admin/webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	webhooks, err := h.controller.List(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// Delete handles DELETE /api/v1/admin/webhooks/:id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.controller.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries handles GET /api/v1/admin/webhooks/:id/deliveries?status=failed&limit=20
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		c.Error(apperrors.Validation("invalid limit parameter").WithDetail("limit", limitStr))
+		return
+	}
+
+	deliveries, err := h.controller.Deliveries(c.Request.Context(), id, c.Query("status"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// Test handles POST /api/v1/admin/webhooks/:id/test, queueing a ping event
+func (h *WebhookHandler) Test(c *gin.Context) {
+	id, ok := webhookID(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.controller.Test(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+func webhookID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperrors.Validation("invalid webhook id").WithDetail("id", c.Param("id")))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "leaderboard"
+
+var (
+	// HTTPRequestDuration tracks request latency per route
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// RateLimited counts requests rejected by the rate limiter
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected with 429 by the rate limiter, by route.",
+	}, []string{"route"})
+
+	// PageCacheRequests counts leaderboard page cache lookups
+	PageCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "page_cache_requests_total",
+		Help:      "Leaderboard page cache lookups, by result (hit/miss).",
+	}, []string{"result"})
+
+	// PageCacheInvalidations counts cached pages dropped by rating changes
+	PageCacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "page_cache_invalidations_total",
+		Help:      "Cached leaderboard pages dropped, by kind (range/all).",
+	}, []string{"kind"})
+
+	// RedisHits counts reads served from Redis
+	RedisHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_hits_total",
+		Help:      "Reads served from Redis, by operation.",
+	}, []string{"operation"})
+
+	// RedisFallbacks counts reads that fell back from Redis to Postgres
+	RedisFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_fallbacks_total",
+		Help:      "Reads that fell back from Redis to Postgres, by operation and reason.",
+	}, []string{"operation", "reason"})
+
+	// RedisCircuitOpen reports whether the Redis circuit breaker is open
+	RedisCircuitOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "redis_circuit_open",
+		Help:      "1 while the Redis circuit breaker is open and Redis is bypassed, 0 otherwise.",
+	})
+
+	// RedisCircuitTransitions counts Redis circuit breaker state changes
+	RedisCircuitTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_circuit_transitions_total",
+		Help:      "Redis circuit breaker state changes, by new state (open/closed).",
+	}, []string{"state"})
+
+	// RedisCommandDuration tracks latency of Redis commands issued by the repository
+	RedisCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Latency of Redis commands by operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation"})
+
+	// DBQueryDuration tracks latency of Postgres queries issued by the repository
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of Postgres queries by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	// DBWriteFailures counts failed Postgres writes
+	DBWriteFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_write_failures_total",
+		Help:      "Failed Postgres writes by operation.",
+	}, []string{"operation"})
+
+	// RedisWriteFailures counts failed Redis writes
+	RedisWriteFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_write_failures_total",
+		Help:      "Failed Redis writes by operation.",
+	}, []string{"operation"})
+
+	// UpdatesProcessed counts rating updates handled by the workers
+	UpdatesProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_processed_total",
+		Help:      "Rating updates processed by the update workers, by result.",
+	}, []string{"result"})
+
+	// UpdatesDropped counts updates rejected because the queue was full
+	UpdatesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_dropped_total",
+		Help:      "Rating updates dropped because the update queue was full.",
+	})
+
+	// UpdateDuration tracks how long a worker takes to apply one update
+	UpdateDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_duration_seconds",
+		Help:      "Time taken by a worker to apply a single rating update.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// ProfileComponentFailures counts profile parts left out because they
+	// failed or missed their deadline
+	ProfileComponentFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "profile_component_failures_total",
+		Help:      "Profile components omitted from a response, by component and reason.",
+	}, []string{"component", "reason"})
+
+	// JobRuns counts scheduled job runs by outcome. Runs skipped because
+	// another instance held the lock are counted as "locked".
+	JobRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Scheduled job runs, by job and status.",
+	}, []string{"job", "status"})
+
+	// JobDuration tracks how long scheduled jobs take
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Duration of scheduled job runs, by job.",
+		Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 1800},
+	}, []string{"job"})
+
+	// AntiCheatHits counts anti-cheat rules firing on rating updates
+	AntiCheatHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "anticheat_rule_hits_total",
+		Help:      "Anti-cheat rules fired on rating updates, by rule and action.",
+	}, []string{"rule", "action"})
+
+	// WebhookDeliveries counts webhook delivery attempts by outcome: succeeded,
+	// retry when another attempt is scheduled, or failed when none is left
+	WebhookDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Webhook delivery attempts, by event type and result.",
+	}, []string{"event_type", "result"})
+
+	// WebhookDeliveryDuration tracks how long webhook endpoints take to answer
+	WebhookDeliveryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_delivery_duration_seconds",
+		Help:      "Duration of webhook delivery attempts.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	// EventsPublished counts domain events by outcome: published, or dropped
+	// when Redis was unavailable or the append failed
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_published_total",
+		Help:      "Domain events appended to the event stream, by type and result.",
+	}, []string{"type", "result"})
+
+	// AchievementsAwarded counts achievements awarded, by achievement ID
+	AchievementsAwarded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "achievements_awarded_total",
+		Help:      "Achievements awarded, by achievement.",
+	}, []string{"achievement"})
+
+	// RedisSyncDuration tracks full Postgres to Redis syncs
+	RedisSyncDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_sync_duration_seconds",
+		Help:      "Duration of full Postgres to Redis syncs, by result.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"result"})
+)
+
+var registerQueueOnce sync.Once
+
+// RegisterQueue exposes the depth and capacity of the update queue.
+// depth is evaluated on every scrape. Only the first call has any effect.
+func RegisterQueue(depth func() int, capacity int) {
+	registerQueueOnce.Do(func() {
+		registerQueue(depth, capacity)
+	})
+}
+
+func registerQueue(depth func() int, capacity int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "update_queue_depth",
+		Help:      "Number of rating updates waiting in the update queue.",
+	}, func() float64 { return float64(depth()) })
+
+	promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "update_queue_capacity",
+		Help:      "Capacity of the update queue.",
+	}).Set(float64(capacity))
+}
+
+// ObserveSince records the time elapsed since start on the given observer
+func ObserveSince(o prometheus.Observer, start time.Time) {
+	o.Observe(time.Since(start).Seconds())
+}
+
+// Result maps an error to a "success"/"error" label value
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
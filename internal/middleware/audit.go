@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"log"
+
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditRecorder appends events to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, event *models.AuditEvent) error
+}
+
+// Audit records the request as action in the audit log once the handler
+// returns, whether it succeeded or not. The target defaults to the route's
+// first path parameter; services can replace it and add the target's state
+// before and after through the audit package.
+func Audit(recorder AuditRecorder, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, record := audit.Begin(c.Request.Context())
+		if len(c.Params) > 0 {
+			audit.SetTarget(ctx, audit.TargetType(action), c.Params[0].Value)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		var err error
+		if last := c.Errors.Last(); last != nil {
+			err = last.Err
+		}
+		// Record the action even if the request's deadline has passed
+		if err := recorder.Record(context.WithoutCancel(ctx), record.Event(ctx, action, err)); err != nil {
+			log.Printf("Failed to record audit event %s for %s %s: %v", action, c.Request.Method, c.Request.URL.Path, err)
+		}
+	}
+}
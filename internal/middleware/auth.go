@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrincipalKey is the gin context key holding the authenticated *auth.Principal
+const PrincipalKey = "principal"
+
+// Authenticator resolves a bearer credential to a principal
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*auth.Principal, error)
+}
+
+// Authenticate resolves credentials from "Authorization: Bearer <key-or-jwt>" or
+// "X-API-Key: <key>". Requests without credentials continue anonymously and are
+// rejected by RequireScope where needed; invalid credentials are rejected here.
+func Authenticate(authenticator Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential := credentialFrom(c)
+		if credential == "" {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), credential)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose principal lacks scope:
+// 401 when no credentials were sent, 403 when the scope is missing.
+func RequireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c.Request.Context())
+		if principal == nil {
+			c.Header("WWW-Authenticate", `Bearer realm="leaderboard"`)
+			c.Error(apperrors.Unauthorized("authentication required"))
+			c.Abort()
+			return
+		}
+		if !principal.HasScope(scope) {
+			c.Error(apperrors.Forbidden("missing required scope %q", scope).WithDetail("scope", scope))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func credentialFrom(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors turns the last error attached with c.Error into the standard error envelope.
+// Handlers only need to call c.Error(err) and return.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperrors.As(c.Errors.Last().Err)
+		// Drivers often surface an expired deadline as their own error
+		if appErr.Kind == apperrors.KindInternal && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+			appErr = &apperrors.Error{Kind: apperrors.KindTimeout, Message: "request timed out", Err: appErr.Err}
+		}
+		status := apperrors.HTTPStatus(appErr.Kind)
+		if status >= 500 {
+			log.Printf("%s %s failed: %v", c.Request.Method, c.Request.URL.Path, appErr)
+		}
+
+		c.AbortWithStatusJSON(status, models.Response{
+			Status:  "error",
+			Message: appErr.Message,
+			Error: &models.APIError{
+				Code:    string(appErr.Kind),
+				Message: appErr.Message,
+				Details: appErr.Details,
+			},
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request latency per route.
+// The route label uses the registered path template (e.g. /api/v1/users/:username/rank)
+// so that path parameters don't explode label cardinality.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
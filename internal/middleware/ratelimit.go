@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/auth"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit enforces rule per client on the named route. Clients are
+// identified by their authenticated principal, or by IP when anonymous, so it
+// must run after Authenticate. Responses carry RateLimit-* headers and
+// rejected requests get a 429 with Retry-After.
+func RateLimit(limiter ratelimit.Limiter, route string, rule ratelimit.Rule) gin.HandlerFunc {
+	if !rule.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%d", rule.Limit, seconds(rule.Period))
+
+	return func(c *gin.Context) {
+		client := "ip:" + c.ClientIP()
+		if principal := auth.PrincipalFrom(c.Request.Context()); principal != nil {
+			client = principal.Subject
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), route+":"+client, rule)
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		reset := seconds(result.Reset)
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(route).Inc()
+			c.Header("Retry-After", strconv.Itoa(reset))
+			c.Error(apperrors.RateLimited("rate limit exceeded, retry in %ds", reset).
+				WithDetail("limit", result.Limit).
+				WithDetail("retry_after", reset))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up to whole seconds, never returning less than 1
+func seconds(d time.Duration) int {
+	return int(math.Max(1, math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"matiks/leaderboard/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID reuses a well-formed X-Request-ID from the client or generates
+// one, echoes it in the response and stores it on the request context
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.With(c.Request.Context(), id))
+		c.Next()
+	}
+}
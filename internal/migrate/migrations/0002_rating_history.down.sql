@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rating_history;
+DROP TABLE IF EXISTS matches;
@@ -0,0 +1,27 @@
+-- Match records and a per-user log of rating changes. Rating changes not caused
+-- by a match (score submissions, admin edits, simulation) have no match_id.
+
+CREATE TABLE matches (
+    id            BIGSERIAL PRIMARY KEY,
+    player_one_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    player_two_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- NULL for a draw
+    winner_id     BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    played_at     TIMESTAMPTZ NOT NULL,
+    CONSTRAINT chk_matches_players CHECK (player_one_id <> player_two_id)
+);
+
+CREATE INDEX idx_matches_played_at ON matches (played_at DESC);
+
+CREATE TABLE rating_history (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    old_rating BIGINT NOT NULL,
+    new_rating BIGINT NOT NULL,
+    source     TEXT NOT NULL,
+    match_id   BIGINT REFERENCES matches (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_rating_history_user_created ON rating_history (user_id, created_at DESC);
+CREATE INDEX idx_rating_history_match ON rating_history (match_id) WHERE match_id IS NOT NULL;
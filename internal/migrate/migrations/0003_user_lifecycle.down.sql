@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_ranked_rating;
+CREATE INDEX IF NOT EXISTS idx_users_rating ON users (rating DESC);
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS hidden_at;
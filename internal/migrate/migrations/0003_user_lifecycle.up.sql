@@ -0,0 +1,14 @@
+-- Hidden and banned users keep their data but leave the leaderboard; deleted
+-- users are soft-deleted and keep their username reserved.
+
+ALTER TABLE users
+    ADD COLUMN hidden_at  TIMESTAMPTZ,
+    ADD COLUMN banned_at  TIMESTAMPTZ,
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+-- Every ranking query filters to visible users, so index only those
+DROP INDEX idx_users_rating;
+CREATE INDEX idx_users_ranked_rating ON users (rating DESC, id)
+    WHERE hidden_at IS NULL AND banned_at IS NULL AND deleted_at IS NULL;
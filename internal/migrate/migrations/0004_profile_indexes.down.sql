@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_matches_player_two;
+DROP INDEX IF EXISTS idx_matches_player_one;
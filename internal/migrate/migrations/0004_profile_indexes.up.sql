@@ -0,0 +1,3 @@
+-- Profiles count each user's matches, which needs per-player lookups
+CREATE INDEX idx_matches_player_one ON matches (player_one_id);
+CREATE INDEX idx_matches_player_two ON matches (player_two_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_ranked_peak_rating;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS peak_rank_at,
+    DROP COLUMN IF EXISTS peak_rank,
+    DROP COLUMN IF EXISTS peak_rating_at,
+    DROP COLUMN IF EXISTS peak_rating;
@@ -0,0 +1,23 @@
+-- All-time bests per user. peak_rank is filled in by the rank sweep.
+
+ALTER TABLE users
+    ADD COLUMN peak_rating    BIGINT,
+    ADD COLUMN peak_rating_at TIMESTAMPTZ,
+    ADD COLUMN peak_rank      BIGINT,
+    ADD COLUMN peak_rank_at   TIMESTAMPTZ;
+
+-- Backfill from rating history; users without history peak at their rating
+UPDATE users SET peak_rating = rating, peak_rating_at = COALESCE(updated_at, created_at, now());
+
+UPDATE users u SET peak_rating = p.new_rating, peak_rating_at = p.created_at
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, new_rating, created_at
+    FROM rating_history
+    ORDER BY user_id, new_rating DESC, created_at ASC
+) p
+WHERE p.user_id = u.id AND p.new_rating >= u.rating;
+
+ALTER TABLE users ALTER COLUMN peak_rating SET NOT NULL;
+
+CREATE INDEX idx_users_ranked_peak_rating ON users (peak_rating DESC, id)
+    WHERE hidden_at IS NULL AND banned_at IS NULL AND deleted_at IS NULL;
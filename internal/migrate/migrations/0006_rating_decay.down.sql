@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS decay_runs;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS hidden_reason,
+    DROP COLUMN IF EXISTS last_active_at;
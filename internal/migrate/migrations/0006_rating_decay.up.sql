@@ -0,0 +1,33 @@
+-- Inactivity tracking for rating decay. last_active_at moves with every
+-- rating change except decay itself; hidden_reason tells users hidden by an
+-- admin apart from those hidden for inactivity, who return when they play.
+
+ALTER TABLE users
+    ADD COLUMN last_active_at TIMESTAMPTZ,
+    ADD COLUMN hidden_reason  TEXT;
+
+UPDATE users u SET last_active_at = COALESCE(
+    (SELECT max(created_at) FROM rating_history h WHERE h.user_id = u.id),
+    u.updated_at, u.created_at, now());
+
+ALTER TABLE users
+    ALTER COLUMN last_active_at SET NOT NULL,
+    ALTER COLUMN last_active_at SET DEFAULT now();
+
+UPDATE users SET hidden_reason = 'admin' WHERE hidden_at IS NOT NULL;
+
+-- One row per decay run, dry runs included
+CREATE TABLE decay_runs (
+    id             BIGSERIAL PRIMARY KEY,
+    started_at     TIMESTAMPTZ NOT NULL,
+    finished_at    TIMESTAMPTZ,
+    dry_run        BOOLEAN NOT NULL,
+    mode           TEXT NOT NULL,
+    examined       BIGINT NOT NULL DEFAULT 0,
+    decayed        BIGINT NOT NULL DEFAULT 0,
+    hidden         BIGINT NOT NULL DEFAULT 0,
+    points_removed BIGINT NOT NULL DEFAULT 0,
+    error          TEXT
+);
+
+CREATE INDEX idx_decay_runs_started_at ON decay_runs (started_at DESC);
@@ -0,0 +1,40 @@
+-- Scheduled job state and history. scheduled_jobs holds what survives a
+-- restart (whether a job is paused); job_runs has one row per run on any
+-- instance.
+CREATE TABLE scheduled_jobs (
+    name       TEXT PRIMARY KEY,
+    paused     BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE job_runs (
+    id          BIGSERIAL PRIMARY KEY,
+    job         TEXT NOT NULL,
+    trigger     TEXT NOT NULL,
+    instance    TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    summary     TEXT,
+    error       TEXT
+);
+
+CREATE INDEX idx_job_runs_job_started_at ON job_runs (job, started_at DESC);
+
+-- Periodic copies of the top of the leaderboard
+CREATE TABLE leaderboard_snapshots (
+    id          BIGSERIAL PRIMARY KEY,
+    taken_at    TIMESTAMPTZ NOT NULL,
+    total_users BIGINT NOT NULL
+);
+
+CREATE TABLE leaderboard_snapshot_entries (
+    snapshot_id BIGINT NOT NULL REFERENCES leaderboard_snapshots (id) ON DELETE CASCADE,
+    rank        BIGINT NOT NULL,
+    user_id     BIGINT NOT NULL,
+    username    TEXT NOT NULL,
+    rating      BIGINT NOT NULL,
+    PRIMARY KEY (snapshot_id, user_id)
+);
+
+CREATE INDEX idx_leaderboard_snapshots_taken_at ON leaderboard_snapshots (taken_at DESC);
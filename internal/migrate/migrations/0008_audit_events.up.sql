@@ -0,0 +1,36 @@
+-- Append-only log of privileged actions. The trigger rejects updates,
+-- deletes and truncation, so rows can only be added.
+CREATE TABLE audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor       TEXT NOT NULL,
+    actor_name  TEXT,
+    action      TEXT NOT NULL,
+    target_type TEXT,
+    target      TEXT,
+    before      JSONB,
+    after       JSONB,
+    outcome     TEXT NOT NULL,
+    error       TEXT,
+    request_id  TEXT
+);
+
+CREATE INDEX idx_audit_events_occurred_at ON audit_events (occurred_at DESC, id DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, occurred_at DESC);
+CREATE INDEX idx_audit_events_action ON audit_events (action, occurred_at DESC);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target, occurred_at DESC);
+CREATE INDEX idx_audit_events_request_id ON audit_events (request_id) WHERE request_id IS NOT NULL;
+
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
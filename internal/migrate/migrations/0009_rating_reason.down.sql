@@ -0,0 +1 @@
+ALTER TABLE rating_history DROP COLUMN IF EXISTS reason;
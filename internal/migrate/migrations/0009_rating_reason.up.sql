@@ -0,0 +1,2 @@
+-- Moderators must say why they set, adjust or roll back a rating
+ALTER TABLE rating_history ADD COLUMN reason TEXT;
@@ -0,0 +1,20 @@
+-- Rating updates that tripped an anti-cheat rule. Held updates wait here as
+-- pending until a moderator approves or rejects them.
+CREATE TABLE rating_anomalies (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    username    TEXT NOT NULL,
+    source      TEXT NOT NULL,
+    old_rating  BIGINT NOT NULL,
+    new_rating  BIGINT NOT NULL,
+    action      TEXT NOT NULL,  -- flag, hold or reject
+    rules       JSONB NOT NULL, -- [{"rule", "action", "detail"}]
+    status      TEXT NOT NULL,  -- pending, approved, rejected or flagged
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reviewed_at TIMESTAMPTZ,
+    reviewed_by TEXT,
+    review_note TEXT
+);
+
+CREATE INDEX idx_rating_anomalies_status_created ON rating_anomalies (status, created_at);
+CREATE INDEX idx_rating_anomalies_user ON rating_anomalies (user_id, created_at DESC);
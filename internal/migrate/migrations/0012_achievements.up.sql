@@ -0,0 +1,21 @@
+-- Achievements held by users. achievement_id is a definition ID from the
+-- achievements configuration.
+CREATE TABLE user_achievements (
+    user_id        BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    achievement_id TEXT NOT NULL,
+    awarded_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+CREATE INDEX idx_user_achievements_achievement ON user_achievements (achievement_id);
+
+-- Since when a user has continuously met a time-based achievement's
+-- condition, e.g. being in the top 1%
+CREATE TABLE achievement_progress (
+    user_id        BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    achievement_id TEXT NOT NULL,
+    since          TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+CREATE INDEX idx_achievement_progress_achievement ON achievement_progress (achievement_id, since);
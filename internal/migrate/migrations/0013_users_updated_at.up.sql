@@ -0,0 +1,2 @@
+-- Redis catch-ups re-read the users changed since a sync started
+CREATE INDEX idx_users_updated_at ON users (updated_at);
@@ -1,6 +1,20 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rating bounds, mirrored by the check constraint on users.rating
+const (
+	MinRating = 100
+	MaxRating = 5000
+	// DefaultRating is given to newly registered users
+	DefaultRating = 1000
+)
 
 // User model
 type User struct {
@@ -10,6 +24,119 @@ type User struct {
 	Rank      int       `json:"rank,omitempty" gorm:"-"` // Calculated field, not stored in DB
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// HiddenAt and BannedAt take a user off the leaderboard without losing
+	// their data; banned users also cannot submit ratings
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+	BannedAt *time.Time `json:"banned_at,omitempty"`
+	// HiddenReason is HiddenAdmin or HiddenInactive while HiddenAt is set
+	HiddenReason *string `json:"hidden_reason,omitempty"`
+	// LastActiveAt is the last rating change other than decay
+	LastActiveAt time.Time `json:"last_active_at" gorm:"not null"`
+	// DeletedAt soft-deletes the user; GORM skips deleted rows in every query
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	// PeakRating and PeakRank are the user's all-time bests and when they
+	// were first reached. PeakRank is nil until the user has been ranked.
+	PeakRating   int        `json:"peak_rating" gorm:"not null"`
+	PeakRatingAt *time.Time `json:"peak_rating_at,omitempty"`
+	PeakRank     *int       `json:"peak_rank,omitempty"`
+	PeakRankAt   *time.Time `json:"peak_rank_at,omitempty"`
+}
+
+// Reasons a user is hidden
+const (
+	HiddenAdmin = "admin"
+	// HiddenInactive users were hidden by rating decay and return to the
+	// leaderboard on their next rating change
+	HiddenInactive = "inactive"
+)
+
+// HiddenFor reports whether the user is hidden for the given reason
+func (u *User) HiddenFor(reason string) bool {
+	return u.HiddenAt != nil && u.HiddenReason != nil && *u.HiddenReason == reason
+}
+
+// RaisePeakRating makes the user's current rating their peak if it is a new
+// best, reporting whether it was
+func (u *User) RaisePeakRating(at time.Time) bool {
+	if u.Rating <= u.PeakRating && u.PeakRatingAt != nil {
+		return false
+	}
+	u.PeakRating, u.PeakRatingAt = u.Rating, &at
+	return true
+}
+
+// Ranked reports whether the user appears on the leaderboard. Queries use
+// the same rule through the repository's rankedUserCondition.
+func (u *User) Ranked() bool {
+	return u.HiddenAt == nil && u.BannedAt == nil && !u.DeletedAt.Valid
+}
+
+// APIKey is a hashed API credential with a comma-separated list of scopes.
+// The plaintext key is only returned once, when the key is created.
+type APIKey struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null;index"`
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"`
+	Scopes     string     `json:"scopes" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKey is returned when a key is created; Key is never shown again
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Rating change sources recorded in rating_history
+const (
+	RatingSourceMatch      = "match"
+	RatingSourceSubmission = "submission"
+	RatingSourceSimulation = "simulation"
+	RatingSourceImport     = "import"
+	RatingSourceDecay      = "decay"
+	// RatingSourceAdmin is a rating set or adjusted by a moderator
+	RatingSourceAdmin = "admin"
+	// RatingSourceRollback reverts earlier changes
+	RatingSourceRollback = "rollback"
+)
+
+// CountsAsActivity reports whether a rating change from source shows the user
+// is still playing. Decay and moderator changes do not.
+func CountsAsActivity(source string) bool {
+	switch source {
+	case RatingSourceDecay, RatingSourceAdmin, RatingSourceRollback:
+		return false
+	}
+	return true
+}
+
+// Match is a game between two users; WinnerID is nil for a draw
+type Match struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	PlayerOneID int       `json:"player_one_id"`
+	PlayerTwoID int       `json:"player_two_id"`
+	WinnerID    *int      `json:"winner_id"`
+	PlayedAt    time.Time `json:"played_at"`
+}
+
+// RatingHistory records one change to a user's rating
+type RatingHistory struct {
+	ID        int    `json:"id" gorm:"primaryKey"`
+	UserID    int    `json:"user_id"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	Source    string `json:"source"`
+	MatchID   *int   `json:"match_id,omitempty"`
+	// Reason is given for moderator changes
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RatingHistory) TableName() string {
+	return "rating_history"
 }
 
 // LeaderboardEntry represents a single entry in the leaderboard
@@ -42,9 +169,559 @@ type UserRankResponse struct {
 	Rank     int    `json:"rank"`
 }
 
-// Generic response wrapper (optional, for error handling)
+// RatingTier is a named band of ratings starting at MinRating
+type RatingTier struct {
+	Name      string
+	MinRating int
+}
+
+// RatingTiers are ordered from the highest band down
+var RatingTiers = []RatingTier{
+	{Name: "grandmaster", MinRating: 4000},
+	{Name: "master", MinRating: 3200},
+	{Name: "diamond", MinRating: 2600},
+	{Name: "platinum", MinRating: 2000},
+	{Name: "gold", MinRating: 1500},
+	{Name: "silver", MinRating: 1000},
+	{Name: "bronze", MinRating: MinRating},
+}
+
+// TierFor returns the name of the tier a rating falls in
+func TierFor(rating int) string {
+	for _, tier := range RatingTiers {
+		if rating >= tier.MinRating {
+			return tier.Name
+		}
+	}
+	return RatingTiers[len(RatingTiers)-1].Name
+}
+
+// RatingPoint is one rating change shown in a profile trend
+type RatingPoint struct {
+	OldRating int       `json:"old_rating"`
+	NewRating int       `json:"new_rating"`
+	Source    string    `json:"source"`
+	At        time.Time `json:"at"`
+}
+
+// RatingTrend summarises a user's rating changes over the last Days days.
+// Change is the net rating change over the window; Points holds the most
+// recent changes, newest first.
+type RatingTrend struct {
+	Days   int           `json:"days"`
+	Change int           `json:"change"`
+	Points []RatingPoint `json:"points"`
+}
+
+// ProfileNeighbors are the players ranked directly above and below a user,
+// both in leaderboard order
+type ProfileNeighbors struct {
+	Above []LeaderboardEntry `json:"above"`
+	Below []LeaderboardEntry `json:"below"`
+}
+
+// UserProfile is the public profile of a ranked user. Each optional part is
+// loaded separately; parts that failed or timed out are nil and named in
+// Unavailable.
+type UserProfile struct {
+	Username string `json:"username"`
+	Rating   int    `json:"rating"`
+	Tier     string `json:"tier"`
+	Rank     *int   `json:"rank"`
+	// Percentile is the share of ranked players rated at or below the user
+	Percentile     *float64          `json:"percentile"`
+	PeakRating     int               `json:"peak_rating"`
+	PeakRatingAt   *time.Time        `json:"peak_rating_at"`
+	PeakRank       *int              `json:"peak_rank"`
+	PeakRankAt     *time.Time        `json:"peak_rank_at"`
+	GamesPlayed    *int64            `json:"games_played"`
+	Trend          *RatingTrend      `json:"trend"`
+	Neighbors      *ProfileNeighbors `json:"neighbors"`
+	Achievements   []Achievement     `json:"achievements"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	AccountAgeDays int               `json:"account_age_days"`
+	Unavailable    []string          `json:"unavailable,omitempty"`
+}
+
+// HallOfFameEntry is a user ranked by their all-time peak rating. Rank is
+// tie-aware, like leaderboard ranks.
+type HallOfFameEntry struct {
+	Rank         int        `json:"rank"`
+	Username     string     `json:"username"`
+	PeakRating   int        `json:"peak_rating"`
+	PeakRatingAt *time.Time `json:"peak_rating_at"`
+	PeakRank     *int       `json:"peak_rank"`
+	PeakRankAt   *time.Time `json:"peak_rank_at"`
+	Rating       int        `json:"rating"`
+}
+
+// HallOfFameResponse is a page of the hall of fame
+type HallOfFameResponse struct {
+	Entries []HallOfFameEntry `json:"entries"`
+	Page    int               `json:"page"`
+	Limit   int               `json:"limit"`
+	Total   int               `json:"total"`
+}
+
+// Generic response wrapper, used for error responses
 type Response struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   *APIError   `json:"error,omitempty"`
+}
+
+// APIError is the machine-readable part of an error response
+type APIError struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// ImportRowError describes a row that was not imported
+type ImportRowError struct {
+	Line     int    `json:"line"`
+	Username string `json:"username,omitempty"`
+	Error    string `json:"error"`
+}
+
+// ImportReport summarises a bulk import. Errors holds at most the first
+// few hundred failures; Failed counts all of them.
+type ImportReport struct {
+	Rows      int              `json:"rows"`
+	Inserted  int              `json:"inserted"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+	// RedisSynced is false when Redis could not be rebuilt after the import;
+	// RedisError says why
+	RedisSynced bool   `json:"redis_synced"`
+	RedisError  string `json:"redis_error,omitempty"`
+}
+
+// Decay modes
+const (
+	DecayOff    = "off"
+	DecayPoints = "points"
+	DecayHide   = "hide"
+)
+
+// DecayRun records one pass of the inactivity decay job
+type DecayRun struct {
+	ID            int        `json:"id" gorm:"primaryKey"`
+	StartedAt     time.Time  `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	DryRun        bool       `json:"dry_run"`
+	Mode          string     `json:"mode"`
+	Examined      int        `json:"examined"`
+	Decayed       int        `json:"decayed"`
+	Hidden        int        `json:"hidden"`
+	PointsRemoved int        `json:"points_removed"`
+	Error         *string    `json:"error,omitempty"`
+}
+
+// DecayAction is what a decay run did, or would do, to one user
+type DecayAction struct {
+	Username     string    `json:"username"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	// Action is the decay mode applied, DecayPoints or DecayHide
+	Action    string `json:"action"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	// Periods is how many decay periods were applied
+	Periods int `json:"periods,omitempty"`
+}
+
+// DecayReport is the result of a decay run. Actions holds at most the first
+// few hundred actions.
+type DecayReport struct {
+	DecayRun
+	Actions []DecayAction `json:"actions"`
+}
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// Job run statuses
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// ScheduledJob is the persisted state of a scheduled job
+type ScheduledJob struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Paused    bool      `json:"paused"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobRun records one run of a scheduled job on one instance
+type JobRun struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Instance   string     `json:"instance"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Summary    *string    `json:"summary,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+}
+
+// JobInfo describes a registered job for the admin API
+type JobInfo struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Paused   bool       `json:"paused"`
+	NextRun  *time.Time `json:"next_run"`
+	LastRun  *JobRun    `json:"last_run"`
+}
+
+// LeaderboardSnapshot is a copy of the top of the leaderboard taken by the
+// snapshot job
+type LeaderboardSnapshot struct {
+	ID         int       `json:"id" gorm:"primaryKey"`
+	TakenAt    time.Time `json:"taken_at"`
+	TotalUsers int64     `json:"total_users"`
+}
+
+// Audit event outcomes
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent is one privileged action, recorded whether or not it succeeded.
+// Before and After hold the target's state around the change, as JSON.
+type AuditEvent struct {
+	ID         int             `json:"id" gorm:"primaryKey"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	ActorName  *string         `json:"actor_name,omitempty"`
+	Action     string          `json:"action"`
+	TargetType *string         `json:"target_type,omitempty"`
+	Target     *string         `json:"target,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"type:jsonb"`
+	After      json.RawMessage `json:"after,omitempty" gorm:"type:jsonb"`
+	Outcome    string          `json:"outcome"`
+	Error      *string         `json:"error,omitempty"`
+	RequestID  *string         `json:"request_id,omitempty"`
+}
+
+// AuditLogResponse is a page of audit events, newest first
+type AuditLogResponse struct {
+	Events []AuditEvent `json:"events"`
+	Page   int          `json:"page"`
+	Limit  int          `json:"limit"`
+	Total  int64        `json:"total"`
+}
+
+// UserAuditState is the part of a user recorded in the audit log
+type UserAuditState struct {
+	ID           int        `json:"id"`
+	Username     string     `json:"username"`
+	Rating       int        `json:"rating"`
+	HiddenAt     *time.Time `json:"hidden_at,omitempty"`
+	HiddenReason *string    `json:"hidden_reason,omitempty"`
+	BannedAt     *time.Time `json:"banned_at,omitempty"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// AuditState returns the user's state for the audit log
+func (u User) AuditState() UserAuditState {
+	state := UserAuditState{
+		ID:           u.ID,
+		Username:     u.Username,
+		Rating:       u.Rating,
+		HiddenAt:     u.HiddenAt,
+		HiddenReason: u.HiddenReason,
+		BannedAt:     u.BannedAt,
+	}
+	if u.DeletedAt.Valid {
+		state.DeletedAt = &u.DeletedAt.Time
+	}
+	return state
+}
+
+// RatingCorrection is a moderator's change to one user's rating
+type RatingCorrection struct {
+	Username  string `json:"username"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	Reason    string `json:"reason"`
+}
+
+// Rollback statuses
+const (
+	RollbackApplied   = "rolled_back"
+	RollbackUnchanged = "unchanged"
+	RollbackNotFound  = "not_found"
+	RollbackFailed    = "failed"
+)
+
+// RatingRollback is the result of rolling back one user's rating changes
+type RatingRollback struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	// Changes is how many rating changes were netted off, earlier rollbacks
+	// included
+	Changes   int64  `json:"changes"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkRollbackReport is the result of rolling back a list of users
+type BulkRollbackReport struct {
+	Since      time.Time        `json:"since"`
+	MatchID    *int             `json:"match_id,omitempty"`
+	RolledBack int              `json:"rolled_back"`
+	Unchanged  int              `json:"unchanged"`
+	NotFound   int              `json:"not_found"`
+	Failed     int              `json:"failed"`
+	Results    []RatingRollback `json:"results"`
+}
+
+// Anti-cheat actions, from least to most severe
+const (
+	// AntiCheatFlag applies the update and records it for review
+	AntiCheatFlag = "flag"
+	// AntiCheatHold keeps the update out until a moderator approves it
+	AntiCheatHold = "hold"
+	// AntiCheatReject drops the update
+	AntiCheatReject = "reject"
+)
+
+// Anomaly statuses
+const (
+	// AnomalyPending is a held update awaiting review
+	AnomalyPending  = "pending"
+	AnomalyApproved = "approved"
+	AnomalyRejected = "rejected"
+	// AnomalyFlagged is an update that was applied but looked suspicious
+	AnomalyFlagged = "flagged"
+)
+
+// RuleHit is one anti-cheat rule that fired on an update
+type RuleHit struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Detail string `json:"detail"`
+}
+
+// RatingAnomaly is a rating update that tripped an anti-cheat rule. Held
+// updates wait in the review queue as AnomalyPending.
+type RatingAnomaly struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Source    string    `json:"source"`
+	OldRating int       `json:"old_rating"`
+	NewRating int       `json:"new_rating"`
+	Action    string    `json:"action"`
+	Rules     []RuleHit `json:"rules" gorm:"serializer:json"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// ReviewedAt, ReviewedBy and ReviewNote are set when a moderator
+	// approves or rejects a held update
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	ReviewedBy *string    `json:"reviewed_by,omitempty"`
+	ReviewNote *string    `json:"review_note,omitempty"`
+}
+
+// AnomalyListResponse is a page of anomalies, oldest first
+type AnomalyListResponse struct {
+	Anomalies []RatingAnomaly `json:"anomalies"`
+	Page      int             `json:"page"`
+	Limit     int             `json:"limit"`
+	Total     int64           `json:"total"`
+}
+
+// Webhook event types
+const (
+	// EventTopEntered is sent when a user climbs into the top N
+	EventTopEntered = "leaderboard.top_entered"
+	// EventNewLeader is sent when a user climbs to #1
+	EventNewLeader = "leaderboard.new_leader"
+	// EventTierReached is sent when a user climbs into a higher rating tier
+	EventTierReached = "tier.reached"
+	// EventPing is sent to test an endpoint
+	EventPing = "ping"
+)
+
+// WebhookEventTypes are the event types an endpoint can subscribe to
+var WebhookEventTypes = []string{EventTopEntered, EventNewLeader, EventTierReached}
+
+// Webhook delivery statuses. A pending delivery with attempts is waiting to
+// be retried.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a registered endpoint and the event types it receives. Secret
+// signs every payload and is only shown when the webhook is created.
+type Webhook struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	URL         string    `json:"url"`
+	Description *string   `json:"description,omitempty"`
+	EventTypes  []string  `json:"event_types" gorm:"serializer:json"`
+	Secret      string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Subscribes reports whether the webhook receives events of eventType
+func (w Webhook) Subscribes(eventType string) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+// CreatedWebhook is returned when a webhook is created; Secret is never
+// shown again
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookEvent is the payload delivered to webhooks
+type WebhookEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// MilestoneData describes the rating change behind a milestone event
+type MilestoneData struct {
+	Username  string `json:"username"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	// OldRank is nil when the user was not on the leaderboard before
+	OldRank *int `json:"old_rank,omitempty"`
+	NewRank int  `json:"new_rank,omitempty"`
+	// Top is the top-N boundary crossed, for EventTopEntered
+	Top int `json:"top,omitempty"`
+	// Tier is the tier reached, for EventTierReached
+	Tier string `json:"tier,omitempty"`
+}
+
+// WebhookDelivery is one event queued for, or delivered to, one webhook
+type WebhookDelivery struct {
+	ID             int             `json:"id" gorm:"primaryKey"`
+	WebhookID      int             `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// UserAchievement records an achievement awarded to a user
+type UserAchievement struct {
+	UserID        int    `gorm:"primaryKey"`
+	AchievementID string `gorm:"primaryKey"`
+	AwardedAt     time.Time
+}
+
+// Achievement is an achievement a user holds, as shown on user endpoints
+type Achievement struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	AwardedAt   time.Time `json:"awarded_at"`
+}
+
+// UserAchievementsResponse lists a user's achievements, oldest first
+type UserAchievementsResponse struct {
+	Username     string        `json:"username"`
+	Achievements []Achievement `json:"achievements"`
+}
+
+// AchievementStat is how many players hold one achievement. Share is the
+// percentage of ranked players that hold it.
+type AchievementStat struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Criteria    string  `json:"criteria"`
+	Holders     int64   `json:"holders"`
+	Share       float64 `json:"share"`
+}
+
+// AchievementStatsResponse lists every defined achievement with its holders
+type AchievementStatsResponse struct {
+	Achievements []AchievementStat `json:"achievements"`
+	RankedUsers  int64             `json:"ranked_users"`
+}
+
+// Domain event types published to the event stream, and the version of
+// each payload. A version is bumped when a payload changes incompatibly.
+const (
+	EventRatingChanged        = "rating.changed"
+	EventRatingChangedVersion = 1
+)
+
+// RatingChanged is published for every applied rating change. Ranks are nil
+// while the user is off the leaderboard.
+type RatingChanged struct {
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	OldRating int       `json:"old_rating"`
+	NewRating int       `json:"new_rating"`
+	OldRank   *int      `json:"old_rank"`
+	NewRank   *int      `json:"new_rank"`
+	Source    string    `json:"source"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// StreamEvent is one entry of the event stream. ID is the Redis stream ID,
+// which orders events and is the cursor for catching up.
+type StreamEvent struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// EventPage is a batch of events replayed from the stream. Next is the
+// cursor for the following batch. Truncated is set when events after the
+// requested cursor were already trimmed from the stream.
+type EventPage struct {
+	Events    []StreamEvent `json:"events"`
+	Next      string        `json:"next"`
+	Truncated bool          `json:"truncated"`
+}
+
+// Health check statuses
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// HealthCheck is the result of a single readiness check
+type HealthCheck struct {
+	Status    string                 `json:"status"`
+	Message   string                 `json:"message,omitempty"`
+	LatencyMs float64                `json:"latency_ms,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ReadinessResponse is returned by /readyz. Status is "ready", "degraded"
+// (a check warned, still serving) or "not_ready" (a check failed).
+type ReadinessResponse struct {
+	Status    string                 `json:"status"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]HealthCheck `json:"checks"`
 }
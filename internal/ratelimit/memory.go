@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped from memory
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// idleAfter is when the bucket will be full again and can be forgotten
+	idleAfter time.Time
+}
+
+// MemoryLimiter keeps token buckets in process memory.
+// Limits are per instance, so it is only a fallback for when Redis is unavailable.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rule Rule) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	rate := rule.ratePerMs()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(rule.Limit), updated: now}
+		l.buckets[key] = b
+	}
+
+	elapsedMs := float64(now.Sub(b.updated).Milliseconds())
+	b.tokens = math.Min(float64(rule.Limit), b.tokens+math.Max(0, elapsedMs)*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	result := newResult(allowed, b.tokens, rule)
+	b.idleAfter = now.Add(time.Duration((float64(rule.Limit)-b.tokens)/rate) * time.Millisecond)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely; they are
+// indistinguishable from a fresh bucket. Callers must hold l.mu.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.After(b.idleAfter) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
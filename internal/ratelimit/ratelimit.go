@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/repository"
+)
+
+// Rule allows Limit requests per Period, refilled continuously (token bucket).
+// A zero Limit disables limiting.
+type Rule struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Enabled reports whether the rule limits anything
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Period > 0
+}
+
+// ratePerMs is the refill rate in tokens per millisecond
+func (r Rule) ratePerMs() float64 {
+	return float64(r.Limit) / float64(r.Period.Milliseconds())
+}
+
+// String renders the rule in the "<limit>/<period>" form accepted by ParseRule
+func (r Rule) String() string {
+	if !r.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// ParseRule parses "<limit>/<period>", e.g. "100/1m" or "5/1s".
+// "off" or "0" disables limiting.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return Rule{}, nil
+	}
+	limitStr, periodStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("rate limit %q must look like <limit>/<period>", s)
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit count %q", limitStr)
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period < time.Millisecond {
+		return Rule{}, fmt.Errorf("invalid rate limit period %q", periodStr)
+	}
+	return Rule{Limit: limit, Period: period}, nil
+}
+
+// Result describes the outcome of a rate limit check
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again (or, when denied,
+	// until the next request would be allowed)
+	Reset time.Duration
+}
+
+// Limiter checks requests against token buckets
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// New returns a Redis-backed limiter shared by all instances, falling back to
+// a per-process limiter when redisRepo is nil, its circuit is open or Redis errors.
+func New(redisRepo *repository.RedisRepository) Limiter {
+	memory := NewMemoryLimiter()
+	if redisRepo == nil {
+		log.Println("Rate limiting with in-memory buckets (Redis not configured)")
+		return memory
+	}
+	return &redisLimiter{redisRepo: redisRepo, fallback: memory}
+}
+
+type redisLimiter struct {
+	redisRepo *repository.RedisRepository
+	fallback  Limiter
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	allowed, tokens, err := l.redisRepo.TakeToken(ctx, "ratelimit:"+key, rule.Limit, rule.ratePerMs())
+	if err != nil {
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		return l.fallback.Allow(ctx, key, rule)
+	}
+	return newResult(allowed, tokens, rule), nil
+}
+
+func newResult(allowed bool, tokens float64, rule Rule) Result {
+	rate := rule.ratePerMs()
+	var resetMs float64
+	if allowed {
+		resetMs = (float64(rule.Limit) - tokens) / rate
+	} else {
+		resetMs = (1 - tokens) / rate
+	}
+	return Result{
+		Allowed:   allowed,
+		Limit:     rule.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil(resetMs)) * time.Millisecond,
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AchievementRepository stores awarded achievements and the progress of
+// users towards time-based ones
+type AchievementRepository struct {
+	db *gorm.DB
+}
+
+// NewAchievementRepository creates a new AchievementRepository instance
+func NewAchievementRepository(db *gorm.DB) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// HeldBy returns a user's achievements, oldest first
+func (r *AchievementRepository) HeldBy(ctx context.Context, userID int) ([]models.UserAchievement, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_user_achievements"), time.Now())
+
+	var held []models.UserAchievement
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("awarded_at ASC, achievement_id ASC").
+		Find(&held).Error
+	return held, err
+}
+
+// Award gives a user an achievement, reporting false if they already held it
+func (r *AchievementRepository) Award(ctx context.Context, userID int, achievementID string) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`INSERT INTO user_achievements (user_id, achievement_id, awarded_at)
+		VALUES (?, ?, now()) ON CONFLICT DO NOTHING`, userID, achievementID)
+	if result.Error != nil {
+		metrics.DBWriteFailures.WithLabelValues("award_achievement").Inc()
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// StartProgress records that a user meets a time-based achievement's
+// condition from now on, unless they already did, and returns since when
+// they have
+func (r *AchievementRepository) StartProgress(ctx context.Context, userID int, achievementID string) (time.Time, error) {
+	var since time.Time
+	err := r.db.WithContext(ctx).Raw(`INSERT INTO achievement_progress (user_id, achievement_id, since)
+		VALUES (?, ?, now())
+		ON CONFLICT (user_id, achievement_id) DO UPDATE SET since = achievement_progress.since
+		RETURNING since`, userID, achievementID).
+		Scan(&since).Error
+	return since, err
+}
+
+// StopProgress records that a user no longer meets a time-based
+// achievement's condition
+func (r *AchievementRepository) StopProgress(ctx context.Context, userID int, achievementID string) error {
+	return r.db.WithContext(ctx).
+		Exec("DELETE FROM achievement_progress WHERE user_id = ? AND achievement_id = ?", userID, achievementID).Error
+}
+
+// AwardPeakRating gives an achievement to every ranked user whose peak
+// rating is at least rating and returns how many were new
+func (r *AchievementRepository) AwardPeakRating(ctx context.Context, achievementID string, rating int) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("award_peak_rating"), time.Now())
+
+	result := r.db.WithContext(ctx).Exec(`INSERT INTO user_achievements (user_id, achievement_id, awarded_at)
+		SELECT id, ?, now() FROM users
+		WHERE `+rankedUserCondition+` AND peak_rating >= ?
+		ON CONFLICT DO NOTHING`, achievementID, rating)
+	return result.RowsAffected, result.Error
+}
+
+// SweepTop updates the progress of a top-N achievement: ranked users rated at
+// least cutoff meet it, from now if they did not already, and everyone else
+// stops meeting it. Users who have met it since holdSince or earlier are
+// awarded it. Returns how many awards were new.
+func (r *AchievementRepository) SweepTop(ctx context.Context, achievementID string, cutoff int, holdSince time.Time) (awarded int64, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("sweep_top_achievement"), time.Now())
+
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`DELETE FROM achievement_progress p
+			WHERE p.achievement_id = ? AND NOT EXISTS (
+				SELECT 1 FROM users u
+				WHERE u.id = p.user_id AND `+rankedUserCondition+` AND u.rating >= ?)`, achievementID, cutoff).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`INSERT INTO achievement_progress (user_id, achievement_id, since)
+			SELECT u.id, ?, now() FROM users u
+			WHERE `+rankedUserCondition+` AND u.rating >= ?
+				AND NOT EXISTS (SELECT 1 FROM user_achievements a WHERE a.user_id = u.id AND a.achievement_id = ?)
+			ON CONFLICT DO NOTHING`, achievementID, cutoff, achievementID).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Exec(`INSERT INTO user_achievements (user_id, achievement_id, awarded_at)
+			SELECT user_id, achievement_id, now() FROM achievement_progress
+			WHERE achievement_id = ? AND since <= ?
+			ON CONFLICT DO NOTHING`, achievementID, holdSince)
+		if result.Error != nil {
+			return result.Error
+		}
+		awarded = result.RowsAffected
+		// Holders need no more tracking
+		return tx.Exec(`DELETE FROM achievement_progress p
+			WHERE p.achievement_id = ? AND EXISTS (
+				SELECT 1 FROM user_achievements a WHERE a.user_id = p.user_id AND a.achievement_id = p.achievement_id)`, achievementID).Error
+	})
+	return awarded, err
+}
+
+// Holders counts the ranked users holding each achievement, keyed by
+// achievement ID
+func (r *AchievementRepository) Holders(ctx context.Context) (map[string]int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_achievement_holders"), time.Now())
+
+	var rows []struct {
+		AchievementID string
+		Holders       int64
+	}
+	err := r.db.WithContext(ctx).Raw(`SELECT a.achievement_id, count(*) AS holders
+		FROM user_achievements a JOIN users u ON u.id = a.user_id
+		WHERE ` + rankedUserCondition + `
+		GROUP BY a.achievement_id`).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	holders := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		holders[row.AchievementID] = row.Holders
+	}
+	return holders, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAlreadyReviewed is returned when resolving an anomaly that is no longer
+// pending review
+var ErrAlreadyReviewed = errors.New("anomaly already reviewed")
+
+// AntiCheatRepository reads the rating history anti-cheat rules judge and
+// stores the anomalies they find
+type AntiCheatRepository struct {
+	db *gorm.DB
+}
+
+// NewAntiCheatRepository creates a new AntiCheatRepository instance
+func NewAntiCheatRepository(db *gorm.DB) *AntiCheatRepository {
+	return &AntiCheatRepository{db: db}
+}
+
+// RecentChanges returns the net of a user's changes from sources made at or
+// after since, and the rating changes of their last samples such changes,
+// newest first
+func (r *AntiCheatRepository) RecentChanges(ctx context.Context, userID int, sources []string, since time.Time, samples int) (net int, deltas []int, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("anticheat_recent_changes"), time.Now())
+
+	db := r.db.WithContext(ctx).Model(&models.RatingHistory{}).
+		Where("user_id = ? AND source IN ?", userID, sources)
+	err = db.Session(&gorm.Session{}).
+		Where("created_at >= ?", since).
+		Select("COALESCE(sum(new_rating - old_rating), 0)").
+		Scan(&net).Error
+	if err != nil || samples <= 0 {
+		return net, nil, err
+	}
+	err = db.Session(&gorm.Session{}).
+		Order("created_at DESC, id DESC").
+		Limit(samples).
+		Pluck("new_rating - old_rating", &deltas).Error
+	return net, deltas, err
+}
+
+// Create stores an anomaly
+func (r *AntiCheatRepository) Create(ctx context.Context, anomaly *models.RatingAnomaly) error {
+	return r.db.WithContext(ctx).Create(anomaly).Error
+}
+
+// Get returns one anomaly
+func (r *AntiCheatRepository) Get(ctx context.Context, id int) (*models.RatingAnomaly, error) {
+	var anomaly models.RatingAnomaly
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&anomaly).Error; err != nil {
+		return nil, err
+	}
+	return &anomaly, nil
+}
+
+// List returns a page of anomalies with the given status, or of every status
+// when it is empty, oldest first
+func (r *AntiCheatRepository) List(ctx context.Context, status string, page, limit int) ([]models.RatingAnomaly, error) {
+	var anomalies []models.RatingAnomaly
+	err := r.withStatus(ctx, status).
+		Order("created_at ASC, id ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&anomalies).Error
+	return anomalies, err
+}
+
+// Count counts the anomalies List would return across all pages
+func (r *AntiCheatRepository) Count(ctx context.Context, status string) (int64, error) {
+	var count int64
+	err := r.withStatus(ctx, status).Model(&models.RatingAnomaly{}).Count(&count).Error
+	return count, err
+}
+
+func (r *AntiCheatRepository) withStatus(ctx context.Context, status string) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	return db
+}
+
+// Resolve moves a pending anomaly to status, recording who reviewed it. It
+// returns ErrAlreadyReviewed if the anomaly is not pending, so two
+// moderators cannot both resolve it.
+func (r *AntiCheatRepository) Resolve(ctx context.Context, id int, status, reviewer string, note *string) (*models.RatingAnomaly, error) {
+	var anomaly models.RatingAnomaly
+	result := r.db.WithContext(ctx).Model(&anomaly).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND status = ?", id, models.AnomalyPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_at": time.Now(),
+			"reviewed_by": reviewer,
+			"review_note": note,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := r.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrAlreadyReviewed
+	}
+	return &anomaly, nil
+}
+
+// Reopen puts a resolved anomaly back in the review queue
+func (r *AntiCheatRepository) Reopen(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).Model(&models.RatingAnomaly{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":      models.AnomalyPending,
+			"reviewed_at": nil,
+			"reviewed_by": nil,
+			"review_note": nil,
+		}).Error
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository appends to and reads the audit log. There are no update or
+// delete methods; the table rejects both.
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new AuditRepository instance
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// AuditFilter narrows an audit log query; empty fields match everything
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	TargetType string
+	Target     string
+	Outcome    string
+	RequestID  string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+func (f AuditFilter) apply(db *gorm.DB) *gorm.DB {
+	for _, field := range []struct{ column, value string }{
+		{"actor", f.Actor},
+		{"action", f.Action},
+		{"target_type", f.TargetType},
+		{"target", f.Target},
+		{"outcome", f.Outcome},
+		{"request_id", f.RequestID},
+	} {
+		if field.value != "" {
+			db = db.Where(field.column+" = ?", field.value)
+		}
+	}
+	if f.Since != nil {
+		db = db.Where("occurred_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		db = db.Where("occurred_at < ?", *f.Until)
+	}
+	return db
+}
+
+// Record appends an event to the audit log
+func (r *AuditRepository) Record(ctx context.Context, event *models.AuditEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		metrics.DBWriteFailures.WithLabelValues("record_audit_event").Inc()
+		return err
+	}
+	return nil
+}
+
+// List returns a page of events matching filter, newest first
+func (r *AuditRepository) List(ctx context.Context, filter AuditFilter, page, limit int) ([]models.AuditEvent, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("list_audit_events"), time.Now())
+
+	var events []models.AuditEvent
+	err := filter.apply(r.db.WithContext(ctx)).
+		Order("occurred_at DESC, id DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// Count returns how many events match filter
+func (r *AuditRepository) Count(ctx context.Context, filter AuditFilter) (int64, error) {
+	var count int64
+	err := filter.apply(r.db.WithContext(ctx).Model(&models.AuditEvent{})).Count(&count).Error
+	return count, err
+}
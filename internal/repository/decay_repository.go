@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DecayRepository stores the history of rating decay runs
+type DecayRepository struct {
+	db *gorm.DB
+}
+
+// NewDecayRepository creates a new DecayRepository instance
+func NewDecayRepository(db *gorm.DB) *DecayRepository {
+	return &DecayRepository{db: db}
+}
+
+// CreateRun records the start of a decay run
+func (r *DecayRepository) CreateRun(ctx context.Context, run *models.DecayRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// SaveRun stores a run's counters and outcome
+func (r *DecayRepository) SaveRun(ctx context.Context, run *models.DecayRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// ListRuns returns the most recent runs, newest first
+func (r *DecayRepository) ListRuns(ctx context.Context, limit int) ([]models.DecayRun, error) {
+	var runs []models.DecayRun
+	err := r.db.WithContext(ctx).Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// DecayCandidate is a ranked user inactive since before the cutoff, with the
+// number of decay changes made since they were last active
+type DecayCandidate struct {
+	models.User
+	Decays int `gorm:"->"`
+}
+
+// ListInactiveUsers returns up to limit ranked users with an ID above afterID,
+// in ID order, who were last active before inactiveBefore and are rated above
+// minRating
+func (r *UserRepository) ListInactiveUsers(ctx context.Context, inactiveBefore time.Time, minRating, afterID, limit int) ([]DecayCandidate, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("list_inactive_users"), time.Now())
+
+	var candidates []DecayCandidate
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).
+		Select(`users.*, (SELECT count(*) FROM rating_history h
+			WHERE h.user_id = users.id AND h.source = ? AND h.created_at > users.last_active_at) AS decays`,
+			models.RatingSourceDecay).
+		Where("last_active_at < ? AND rating > ? AND id > ?", inactiveBefore, minRating, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	return candidates, err
+}
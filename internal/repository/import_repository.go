@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// ImportRow is one validated user to upsert. Line is its position in the
+// source file, used to report duplicates.
+type ImportRow struct {
+	Line     int
+	Username string
+	Rating   int
+}
+
+// ImportResult counts what ImportUsers changed. Duplicates are rows whose
+// username already appeared on an earlier line and Deleted are rows naming a
+// deleted user; both are skipped.
+type ImportResult struct {
+	Inserted   int
+	Updated    int
+	Unchanged  int
+	Duplicates []ImportRow
+	Deleted    []ImportRow
+}
+
+// ImportUsers streams rows from next into a staging table with COPY, then
+// upserts them by username in the same transaction: new users are inserted,
+// existing users get the imported rating (raising their peak if it is a new
+// best, and counting as activity) and a rating_history row. next
+// returns false when there are no more rows. Nothing is written if any step fails.
+func (r *UserRepository) ImportUsers(ctx context.Context, next func() (ImportRow, bool, error)) (_ *ImportResult, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("import_users"), time.Now())
+	defer func() {
+		if err != nil {
+			metrics.DBWriteFailures.WithLabelValues("import_users").Inc()
+		}
+	}()
+
+	result := &ImportResult{}
+	err = r.withPgxTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `CREATE TEMP TABLE import_staging (
+			line     BIGINT NOT NULL,
+			username TEXT NOT NULL,
+			rating   BIGINT NOT NULL
+		) ON COMMIT DROP`)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_staging"},
+			[]string{"line", "username", "rating"}, &importSource{next: next}); err != nil {
+			return fmt.Errorf("failed to copy rows: %w", err)
+		}
+		if _, err := tx.Exec(ctx, "CREATE INDEX ON import_staging (username, line)"); err != nil {
+			return err
+		}
+
+		// Keep the first occurrence of each username
+		result.Duplicates, err = removeStaged(ctx, tx, `DELETE FROM import_staging s
+			WHERE EXISTS (SELECT 1 FROM import_staging f WHERE f.username = s.username AND f.line < s.line)
+			RETURNING line, username, rating`)
+		if err != nil {
+			return err
+		}
+		// Deleted users keep their username reserved
+		result.Deleted, err = removeStaged(ctx, tx, `DELETE FROM import_staging s
+			USING users u WHERE u.username = s.username AND u.deleted_at IS NOT NULL
+			RETURNING s.line, s.username, s.rating`)
+		if err != nil {
+			return err
+		}
+
+		// Lock and update existing users whose rating changes, logging each change
+		tag, err := tx.Exec(ctx, `WITH changed AS (
+				SELECT u.id, u.rating AS old_rating, s.rating AS new_rating
+				FROM users u JOIN import_staging s ON s.username = u.username
+				WHERE u.rating <> s.rating
+				FOR UPDATE OF u
+			), updated AS (
+				UPDATE users u SET rating = c.new_rating, updated_at = now(), last_active_at = now(),
+					hidden_at = CASE WHEN u.hidden_reason = 'inactive' THEN NULL ELSE u.hidden_at END,
+					hidden_reason = CASE WHEN u.hidden_reason = 'inactive' THEN NULL ELSE u.hidden_reason END,
+					peak_rating_at = CASE WHEN c.new_rating > u.peak_rating THEN now() ELSE u.peak_rating_at END,
+					peak_rating = GREATEST(u.peak_rating, c.new_rating)
+				FROM changed c WHERE u.id = c.id
+			)
+			INSERT INTO rating_history (user_id, old_rating, new_rating, source)
+			SELECT id, old_rating, new_rating, $1 FROM changed`, models.RatingSourceImport)
+		if err != nil {
+			return fmt.Errorf("failed to update existing users: %w", err)
+		}
+		result.Updated = int(tag.RowsAffected())
+
+		tag, err = tx.Exec(ctx, `INSERT INTO users (username, rating, peak_rating, peak_rating_at, last_active_at, created_at, updated_at)
+			SELECT username, rating, rating, now(), now(), now(), now() FROM import_staging s
+			WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.username = s.username)
+			ORDER BY line
+			ON CONFLICT (username) DO NOTHING`)
+		if err != nil {
+			return fmt.Errorf("failed to insert new users: %w", err)
+		}
+		result.Inserted = int(tag.RowsAffected())
+
+		var staged int
+		if err := tx.QueryRow(ctx, "SELECT count(*) FROM import_staging").Scan(&staged); err != nil {
+			return err
+		}
+		result.Unchanged = staged - result.Inserted - result.Updated
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// removeStaged runs a DELETE ... RETURNING on the staging table
+func removeStaged(ctx context.Context, tx pgx.Tx, sql string) ([]ImportRow, error) {
+	rows, err := tx.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (ImportRow, error) {
+		var r ImportRow
+		err := row.Scan(&r.Line, &r.Username, &r.Rating)
+		return r, err
+	})
+}
+
+// withPgxTx runs fn in a transaction on a native pgx connection, for
+// features GORM does not expose such as COPY
+func (r *UserRepository) withPgxTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		return pgx.BeginFunc(ctx, driverConn.(*stdlib.Conn).Conn(), fn)
+	})
+}
+
+// importSource adapts a row iterator to pgx.CopyFromSource
+type importSource struct {
+	next func() (ImportRow, bool, error)
+	row  ImportRow
+	err  error
+}
+
+func (s *importSource) Next() bool {
+	var ok bool
+	s.row, ok, s.err = s.next()
+	return ok && s.err == nil
+}
+
+func (s *importSource) Values() ([]any, error) {
+	return []any{s.row.Line, s.row.Username, s.row.Rating}, nil
+}
+
+func (s *importSource) Err() error {
+	return s.err
+}
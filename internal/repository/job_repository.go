@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository stores scheduled job state and run history
+type JobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new JobRepository instance
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// EnsureJob creates the state row for a job the first time it is registered.
+// An existing row, and the paused flag an admin set on it, is left alone.
+func (r *JobRepository) EnsureJob(ctx context.Context, name string, paused bool) error {
+	job := models.ScheduledJob{Name: name, Paused: paused, UpdatedAt: time.Now()}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&job).Error
+}
+
+// GetJob returns the state of one job
+func (r *JobRepository) GetJob(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	var job models.ScheduledJob
+	if err := r.db.WithContext(ctx).Where("name = ?", name).Take(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs returns the state of every job, keyed by name
+func (r *JobRepository) ListJobs(ctx context.Context) (map[string]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	if err := r.db.WithContext(ctx).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.ScheduledJob, len(jobs))
+	for _, job := range jobs {
+		byName[job.Name] = job
+	}
+	return byName, nil
+}
+
+// SetPaused pauses or resumes a job on every instance
+func (r *JobRepository) SetPaused(ctx context.Context, name string, paused bool) (*models.ScheduledJob, error) {
+	job := models.ScheduledJob{Name: name, Paused: paused, UpdatedAt: time.Now()}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_at"}),
+	}).Create(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CreateRun records the start of a job run
+func (r *JobRepository) CreateRun(ctx context.Context, run *models.JobRun) error {
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// SaveRun stores a run's outcome
+func (r *JobRepository) SaveRun(ctx context.Context, run *models.JobRun) error {
+	return r.db.WithContext(ctx).Save(run).Error
+}
+
+// ListRuns returns the most recent runs of a job, newest first
+func (r *JobRepository) ListRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	err := r.db.WithContext(ctx).Where("job = ?", job).
+		Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// LastRuns returns the most recent run of every job that has run, keyed by job
+func (r *JobRepository) LastRuns(ctx context.Context) (map[string]models.JobRun, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("last_job_runs"), time.Now())
+
+	var runs []models.JobRun
+	err := r.db.WithContext(ctx).Raw(`SELECT DISTINCT ON (job) * FROM job_runs
+		ORDER BY job, started_at DESC, id DESC`).Scan(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	byJob := make(map[string]models.JobRun, len(runs))
+	for _, run := range runs {
+		byJob[run.Job] = run
+	}
+	return byJob, nil
+}
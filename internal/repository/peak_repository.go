@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+)
+
+// RecordPeakRank makes rank the user's peak rank if it beats their current
+// one, and reports whether it did. Users off the leaderboard are left alone.
+func (r *UserRepository) RecordPeakRank(ctx context.Context, userID, rank int) (bool, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("record_peak_rank"), time.Now())
+
+	result := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).
+		Where("id = ? AND (peak_rank IS NULL OR peak_rank > ?)", userID, rank).
+		Updates(map[string]interface{}{"peak_rank": rank, "peak_rank_at": time.Now()})
+	if result.Error != nil {
+		metrics.DBWriteFailures.WithLabelValues("record_peak_rank").Inc()
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SweepPeakRanks ranks every user on the leaderboard and records a new peak
+// rank for each one ranked higher than ever before. It catches the rank gains
+// that come from other users dropping or leaving, and returns how many users
+// got a new peak.
+func (r *UserRepository) SweepPeakRanks(ctx context.Context) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("sweep_peak_ranks"), time.Now())
+
+	result := r.db.WithContext(ctx).Exec(`UPDATE users u SET peak_rank = r.rank, peak_rank_at = now()
+		FROM (
+			SELECT id, RANK() OVER (ORDER BY rating DESC) AS rank
+			FROM users
+			WHERE ` + rankedUserCondition + `
+		) r
+		WHERE u.id = r.id AND (u.peak_rank IS NULL OR r.rank < u.peak_rank)`)
+	if result.Error != nil {
+		metrics.DBWriteFailures.WithLabelValues("sweep_peak_ranks").Inc()
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetHallOfFame returns a page of users on the leaderboard ordered by their
+// all-time peak rating
+func (r *UserRepository) GetHallOfFame(ctx context.Context, page, limit int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_hall_of_fame"), time.Now())
+
+	var users []models.User
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).
+		Order("peak_rating DESC, id ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// CountUsersWithHigherPeakRating counts users on the leaderboard whose peak
+// rating beats the given one, for tie-aware hall of fame ranks
+func (r *UserRepository) CountUsersWithHigherPeakRating(ctx context.Context, peakRating int) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_higher_peak_rating"), time.Now())
+
+	var count int64
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).
+		Where("peak_rating > ?", peakRating).
+		Count(&count).Error
+	return count, err
+}
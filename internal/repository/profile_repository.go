@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CountMatches counts the matches a user played in
+func (r *UserRepository) CountMatches(ctx context.Context, userID int) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_matches"), time.Now())
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Match{}).
+		Where("player_one_id = ? OR player_two_id = ?", userID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetRatingHistorySince returns up to limit of a user's rating changes made
+// after since, newest first
+func (r *UserRepository) GetRatingHistorySince(ctx context.Context, userID int, since time.Time, limit int) ([]models.RatingHistory, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_rating_history"), time.Now())
+
+	var history []models.RatingHistory
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&history).Error
+	return history, err
+}
+
+// GetNeighbors returns up to n ranked users directly above and below user in
+// leaderboard order (rating DESC, then id). Both lists are in leaderboard order.
+func (r *UserRepository) GetNeighbors(ctx context.Context, user *models.User, n int) (above, below []models.User, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_neighbors"), time.Now())
+
+	err = r.db.WithContext(ctx).Scopes(rankedUsers).
+		Where("rating > ? OR (rating = ? AND id < ?)", user.Rating, user.Rating, user.ID).
+		Order("rating ASC, id DESC").
+		Limit(n).
+		Find(&above).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.Reverse(above)
+
+	below, err = r.ListUsersByRating(ctx, user, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return above, below, nil
+}
+
+// GetRatingAt returns a user's rating at the given time, taken from the first
+// change after it. found is false when the rating has not changed since.
+func (r *UserRepository) GetRatingAt(ctx context.Context, userID int, at time.Time) (rating int, found bool, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_rating_at"), time.Now())
+
+	var entry models.RatingHistory
+	err = r.db.WithContext(ctx).
+		Where("user_id = ? AND created_at > ?", userID, at).
+		Order("created_at ASC, id ASC").
+		Take(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return entry.OldRating, true, nil
+}
+
+// NetRatingChangeSince sums a user's rating changes made at or after since,
+// only those linked to matchID when it is set. count is the number of changes.
+func (r *UserRepository) NetRatingChangeSince(ctx context.Context, userID int, since time.Time, matchID *int) (count int64, net int, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("net_rating_change"), time.Now())
+
+	query := r.db.WithContext(ctx).Model(&models.RatingHistory{}).
+		Where("user_id = ? AND created_at >= ?", userID, since)
+	if matchID != nil {
+		query = query.Where("match_id = ?", *matchID)
+	}
+	var result struct {
+		Count int64
+		Net   int
+	}
+	err = query.Select("count(*) AS count, COALESCE(sum(new_rating - old_rating), 0) AS net").
+		Scan(&result).Error
+	return result.Count, result.Net, err
+}
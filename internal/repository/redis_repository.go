@@ -2,47 +2,517 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"strconv"
+	"time"
+
+	"matiks/leaderboard/internal/breaker"
+	"matiks/leaderboard/internal/events"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// leaderboardKey is the sorted set of user IDs scored by rating. IDs, unlike
+// usernames, survive renames.
+const leaderboardKey = "leaderboard:users"
+
+// profilesKey is a hash from user ID to a JSON Profile, used to turn a page of
+// IDs into usernames with one HMGET
+const profilesKey = "leaderboard:profiles"
+
+// legacyLeaderboardKey is the username-keyed sorted set used before members
+// were user IDs. The next full sync deletes it.
+const legacyLeaderboardKey = "leaderboard:ratings"
+
+// probeTimeout bounds a single health probe
+const probeTimeout = time.Second
+
+// ErrRedisUnavailable is returned without contacting Redis while the circuit is open
+var ErrRedisUnavailable = errors.New("redis unavailable: circuit open")
+
+// tokenBucketScript refills and takes one token from the bucket stored at KEYS[1].
+// ARGV: capacity, refill rate in tokens per millisecond.
+// Returns {allowed (0/1), remaining tokens as a string}. Uses the Redis clock so
+// every instance agrees on elapsed time.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// releaseLockScript deletes the lock at KEYS[1] only if it still holds ARGV[1],
+// so an owner whose lock expired cannot release someone else's
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisRepository wraps Redis behind a circuit breaker. After failureThreshold
+// consecutive failed commands the circuit opens and every call returns
+// ErrRedisUnavailable immediately, so callers fall back to Postgres without
+// paying a timeout first. Monitor closes the circuit again once Redis answers
+// and has been re-synced.
 type RedisRepository struct {
-	client *redis.Client
+	client  *redis.Client
+	breaker *breaker.Breaker
+}
+
+// NewRedisRepository starts with the circuit open: Redis is not used until
+// Monitor has seen it respond and synced it from Postgres.
+func NewRedisRepository(client *redis.Client, failureThreshold int) *RedisRepository {
+	metrics.RedisCircuitOpen.Set(1)
+	return &RedisRepository{
+		client:  client,
+		breaker: breaker.New(failureThreshold, breaker.Open, logTransition),
+	}
+}
+
+func logTransition(_, to breaker.State) {
+	if to == breaker.Open {
+		log.Println("Redis circuit opened: bypassing Redis until it recovers")
+		metrics.RedisCircuitOpen.Set(1)
+	} else {
+		log.Println("Redis circuit closed: serving from Redis again")
+		metrics.RedisCircuitOpen.Set(0)
+	}
+	metrics.RedisCircuitTransitions.WithLabelValues(to.String()).Inc()
+}
+
+// Available reports whether Redis is configured and the circuit is closed
+func (r *RedisRepository) Available() bool {
+	return r != nil && r.breaker.Allow()
+}
+
+// CircuitState returns the breaker state and when it was entered
+func (r *RedisRepository) CircuitState() (breaker.State, time.Time) {
+	return r.breaker.State()
 }
 
-func NewRedisRepository(client *redis.Client) *RedisRepository {
-	return &RedisRepository{client: client}
+// do runs fn if the circuit is closed and feeds its outcome to the breaker.
+// Errors caused by ctx ending, rather than by Redis, are not counted.
+func (r *RedisRepository) do(ctx context.Context, fn func() error) error {
+	if !r.breaker.Allow() {
+		return ErrRedisUnavailable
+	}
+	err := fn()
+	var replyErr redis.Error
+	switch {
+	case err == nil, errors.Is(err, redis.Nil), errors.As(err, &replyErr):
+		// Redis answered, even if the answer was an error reply
+		r.breaker.Success()
+	case errors.Is(err, context.Canceled), ctx.Err() != nil:
+		// The caller gave up or ran out of time; that says nothing about Redis
+	default:
+		// Includes the client's own read/write timeouts
+		r.breaker.Failure()
+	}
+	return err
 }
 
-func (r *RedisRepository) AddToLeaderboard(ctx context.Context, username string, rating int) error {
-	return r.client.ZAdd(ctx, "leaderboard:ratings", redis.Z{
-		Score:  float64(rating),
-		Member: username,
-	}).Err()
+// Monitor pings Redis every interval until ctx is canceled. A failed ping
+// opens the circuit. When a ping succeeds while the circuit is open, resync
+// rebuilds Redis from Postgres and the circuit closes only if that succeeded.
+// Writes skipped between resync reading Postgres and the circuit closing are
+// then replayed by catchUp with the time the resync started, so reads never
+// see a sorted set that missed updates during the outage.
+func (r *RedisRepository) Monitor(ctx context.Context, interval time.Duration, resync func(context.Context) error, catchUp func(context.Context, time.Time) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.probe(ctx, resync, catchUp)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *RedisRepository) probe(ctx context.Context, resync func(context.Context) error, catchUp func(context.Context, time.Time) error) {
+	pingCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	err := r.client.Ping(pingCtx).Err()
+	cancel()
+	if err != nil {
+		if r.breaker.Allow() {
+			log.Printf("Redis ping failed: %v", err)
+		}
+		r.breaker.Trip()
+		return
+	}
+	if r.breaker.Allow() {
+		return
+	}
+
+	log.Println("Redis is reachable, re-syncing from database...")
+	start := time.Now()
+	if err := resync(ctx); err != nil {
+		log.Printf("Failed to re-sync Redis, keeping circuit open: %v", err)
+		return
+	}
+	r.breaker.Reset()
+	// Writes from here on reach Redis themselves; replay the ones skipped
+	// while the circuit was still open
+	if err := catchUp(ctx, start); err != nil {
+		r.MarkStale(err)
+	}
+}
+
+// Profile is what the leaderboard needs to show a user besides their rating
+type Profile struct {
+	Username string `json:"username"`
+}
+
+// Member returns the sorted set member for a user ID
+func Member(userID int) string {
+	return strconv.Itoa(userID)
+}
+
+// MemberID parses a sorted set member back into a user ID
+func MemberID(member interface{}) (int, error) {
+	s, _ := member.(string)
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("leaderboard member %v is not a user ID", member)
+	}
+	return id, nil
+}
+
+// SaveLeaderboardEntry adds or updates a user's score and cached profile in
+// one MULTI
+func (r *RedisRepository) SaveLeaderboardEntry(ctx context.Context, user models.User) error {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("save_leaderboard_entry"), time.Now())
+
+	profile, err := json.Marshal(Profile{Username: user.Username})
+	if err != nil {
+		return err
+	}
+	err = r.do(ctx, func() error {
+		_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZAdd(ctx, leaderboardKey, redis.Z{Score: float64(user.Rating), Member: Member(user.ID)})
+			pipe.HSet(ctx, profilesKey, Member(user.ID), profile)
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		metrics.RedisWriteFailures.WithLabelValues("save_leaderboard_entry").Inc()
+	}
+	return err
+}
+
+// RemoveLeaderboardEntry removes a user's score and cached profile in one MULTI
+func (r *RedisRepository) RemoveLeaderboardEntry(ctx context.Context, userID int) error {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("remove_leaderboard_entry"), time.Now())
+
+	err := r.do(ctx, func() error {
+		_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, leaderboardKey, Member(userID))
+			pipe.HDel(ctx, profilesKey, Member(userID))
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		metrics.RedisWriteFailures.WithLabelValues("remove_leaderboard_entry").Inc()
+	}
+	return err
+}
+
+// GetProfiles returns the cached profiles of the given users with one HMGET.
+// Users without a cached profile are missing from the map.
+func (r *RedisRepository) GetProfiles(ctx context.Context, userIDs []int) (map[int]Profile, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("get_profiles"), time.Now())
+
+	fields := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		fields[i] = Member(id)
+	}
+	var values []interface{}
+	err := r.do(ctx, func() (err error) {
+		values, err = r.client.HMGet(ctx, profilesKey, fields...).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[int]Profile, len(values))
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var profile Profile
+		if err := json.Unmarshal([]byte(raw), &profile); err != nil {
+			continue
+		}
+		profiles[userIDs[i]] = profile
+	}
+	return profiles, nil
+}
+
+// SaveProfiles caches the profiles of the given users
+func (r *RedisRepository) SaveProfiles(ctx context.Context, users []models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	values, err := profileValues(users)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, func() error {
+		return r.client.HSet(ctx, profilesKey, values...).Err()
+	})
+}
+
+// profileValues renders users as HSET field/value pairs for profilesKey
+func profileValues(users []models.User) ([]interface{}, error) {
+	values := make([]interface{}, 0, 2*len(users))
+	for _, user := range users {
+		profile, err := json.Marshal(Profile{Username: user.Username})
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, Member(user.ID), profile)
+	}
+	return values, nil
+}
+
+// MarkStale opens the circuit after a write Redis may not have applied. Monitor
+// re-syncs Redis from Postgres before closing it again, so no write is lost.
+func (r *RedisRepository) MarkStale(cause error) {
+	log.Printf("Redis may have missed a write, forcing a re-sync: %v", cause)
+	r.breaker.Trip()
 }
 
 func (r *RedisRepository) GetLeaderboard(ctx context.Context, offset, limit int64) ([]redis.Z, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("get_leaderboard"), time.Now())
+
 	// ZRevRangeWithScores returns in DESCENDING order (highest score first)
 	// This is what we want for leaderboard (rank 1 = highest rating)
-	return r.client.ZRevRangeWithScores(ctx, "leaderboard:ratings", offset, offset+limit-1).Result()
+	var entries []redis.Z
+	err := r.do(ctx, func() (err error) {
+		entries, err = r.client.ZRevRangeWithScores(ctx, leaderboardKey, offset, offset+limit-1).Result()
+		return err
+	})
+	return entries, err
 }
 
-func (r *RedisRepository) GetUserRank(ctx context.Context, username string) (int64, error) {
-	return r.client.ZRevRank(ctx, "leaderboard:ratings", username).Result()
+func (r *RedisRepository) GetUserRank(ctx context.Context, userID int) (int64, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("get_user_rank"), time.Now())
+
+	var rank int64
+	err := r.do(ctx, func() (err error) {
+		rank, err = r.client.ZRevRank(ctx, leaderboardKey, Member(userID)).Result()
+		return err
+	})
+	return rank, err
 }
 
 // CountUsersWithHigherRating counts users with rating greater than the given rating
 // Used for tie-aware rank calculation
 func (r *RedisRepository) CountUsersWithHigherRating(ctx context.Context, rating int) (int64, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("count_higher_rating"), time.Now())
+
 	// Use ZCOUNT to count members with score > rating
 	// In Redis sorted sets, score is the rating
 	// Format: "(rating" means > rating (exclusive), "+inf" means positive infinity
 	ratingStr := strconv.FormatFloat(float64(rating), 'f', -1, 64)
-	return r.client.ZCount(ctx, "leaderboard:ratings",
-		"("+ratingStr, "+inf").Result()
+	var count int64
+	err := r.do(ctx, func() (err error) {
+		count, err = r.client.ZCount(ctx, leaderboardKey, "("+ratingStr, "+inf").Result()
+		return err
+	})
+	return count, err
 }
 
 func (r *RedisRepository) GetTotalUsers(ctx context.Context) (int64, error) {
-	return r.client.ZCard(ctx, "leaderboard:ratings").Result()
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("get_total_users"), time.Now())
+
+	var count int64
+	err := r.do(ctx, func() (err error) {
+		count, err = r.client.ZCard(ctx, leaderboardKey).Result()
+		return err
+	})
+	return count, err
+}
+
+// ScanLeaderboard calls fn with the whole leaderboard in chunks of batchSize,
+// highest rating first
+func (r *RedisRepository) ScanLeaderboard(ctx context.Context, batchSize int64, fn func([]redis.Z) error) error {
+	for offset := int64(0); ; offset += batchSize {
+		entries, err := r.GetLeaderboard(ctx, offset, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		if err := fn(entries); err != nil {
+			return err
+		}
+		if int64(len(entries)) < batchSize {
+			return nil
+		}
+	}
+}
+
+// Connect pings Redis and closes the circuit if it answers. One-shot commands
+// use it instead of Monitor; it does not re-sync.
+func (r *RedisRepository) Connect(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	if err := r.client.Ping(pingCtx).Err(); err != nil {
+		return err
+	}
+	r.breaker.Reset()
+	return nil
+}
+
+// Close closes the Redis client
+func (r *RedisRepository) Close() error {
+	return r.client.Close()
+}
+
+// TakeToken takes one token from the rate limit bucket at key, refilling it at
+// ratePerMs up to capacity. It returns whether the request is allowed and how
+// many tokens remain.
+func (r *RedisRepository) TakeToken(ctx context.Context, key string, capacity int, ratePerMs float64) (bool, float64, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("take_token"), time.Now())
+
+	var res []interface{}
+	err := r.do(ctx, func() (err error) {
+		res, err = tokenBucketScript.Run(ctx, r.client, []string{key}, capacity, ratePerMs).Slice()
+		return err
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket reply: %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	remainingStr, _ := res[1].(string)
+	remaining, err := strconv.ParseFloat(remainingStr, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected token bucket reply: %v", res)
+	}
+	return allowed == 1, remaining, nil
+}
+
+// AcquireLock takes the lock at key for owner unless someone else holds it.
+// The lock expires after ttl in case the owner dies holding it.
+func (r *RedisRepository) AcquireLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("acquire_lock"), time.Now())
+
+	var acquired bool
+	err := r.do(ctx, func() (err error) {
+		acquired, err = r.client.SetNX(ctx, key, owner, ttl).Result()
+		return err
+	})
+	return acquired, err
+}
+
+// ReleaseLock releases the lock at key if owner still holds it
+func (r *RedisRepository) ReleaseLock(ctx context.Context, key, owner string) error {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("release_lock"), time.Now())
+
+	return r.do(ctx, func() error {
+		return releaseLockScript.Run(ctx, r.client, []string{key}, owner).Err()
+	})
+}
+
+// AppendEvent adds an entry to the event stream, trimming it to roughly
+// maxLen entries, and returns the entry's ID
+func (r *RedisRepository) AppendEvent(ctx context.Context, values []interface{}, maxLen int) (string, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("append_event"), time.Now())
+
+	var id string
+	err := r.do(ctx, func() (err error) {
+		id, err = r.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: events.Stream,
+			MaxLen: int64(maxLen),
+			Approx: true,
+			Values: values,
+		}).Result()
+		return err
+	})
+	return id, err
+}
+
+// ReadEvents returns up to count entries of the event stream from start on,
+// oldest first. start is a stream ID, or "-" for the oldest retained entry.
+func (r *RedisRepository) ReadEvents(ctx context.Context, start string, count int64) ([]redis.XMessage, error) {
+	defer metrics.ObserveSince(metrics.RedisCommandDuration.WithLabelValues("read_events"), time.Now())
+
+	var msgs []redis.XMessage
+	err := r.do(ctx, func() (err error) {
+		msgs, err = r.client.XRangeN(ctx, events.Stream, start, "+", count).Result()
+		return err
+	})
+	return msgs, err
+}
+
+// PublishCacheInvalidation broadcasts a page cache invalidation to other instances
+func (r *RedisRepository) PublishCacheInvalidation(ctx context.Context, message string) error {
+	return r.do(ctx, func() error {
+		return r.client.Publish(ctx, "leaderboard:cache:invalidate", message).Err()
+	})
+}
+
+// SubscribeCacheInvalidations streams invalidations published by any instance
+// until ctx is canceled. The subscription reconnects on its own after an outage.
+func (r *RedisRepository) SubscribeCacheInvalidations(ctx context.Context) <-chan string {
+	pubsub := r.client.Subscribe(ctx, "leaderboard:cache:invalidate")
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
 }
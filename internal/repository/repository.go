@@ -2,11 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"matiks/leaderboard/internal/metrics"
 	"matiks/leaderboard/internal/models"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository handles all database operations for users
@@ -14,6 +20,24 @@ type UserRepository struct {
 	db *gorm.DB
 }
 
+// ErrUsernameTaken is returned when a username belongs to another user,
+// including a deleted one
+var ErrUsernameTaken = errors.New("username is taken")
+
+// MirrorFunc mirrors a user change to Redis. It runs inside the transaction,
+// while the user's row is locked, so Redis sees changes to one user in the
+// same order as the database. before is the zero User for a new user.
+type MirrorFunc func(before, after models.User)
+
+// rankedUserCondition matches users shown on the leaderboard, for the
+// rankedUsers scope and raw SQL alike. It must agree with models.User.Ranked.
+const rankedUserCondition = "hidden_at IS NULL AND banned_at IS NULL AND deleted_at IS NULL"
+
+// rankedUsers limits a query to users shown on the leaderboard
+func rankedUsers(db *gorm.DB) *gorm.DB {
+	return db.Where(rankedUserCondition)
+}
+
 // NewUserRepository creates a new UserRepository instance
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
@@ -21,11 +45,13 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 // GetLeaderboard retrieves users ordered by rating DESC with pagination
 // Returns users without rank calculation (rank is calculated in service layer)
-func (r *UserRepository) GetLeaderboard(page, limit int) ([]models.User, error) {
+func (r *UserRepository) GetLeaderboard(ctx context.Context, page, limit int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_leaderboard"), time.Now())
+
 	offset := (page - 1) * limit
 	var users []models.User
 
-	err := r.db.
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).
 		Order("rating DESC").
 		Limit(limit).
 		Offset(offset).
@@ -35,12 +61,14 @@ func (r *UserRepository) GetLeaderboard(page, limit int) ([]models.User, error)
 }
 
 // SearchUsers searches for users by username pattern (case-insensitive) with pagination
-func (r *UserRepository) SearchUsers(query string, page, limit int) ([]models.User, error) {
+func (r *UserRepository) SearchUsers(ctx context.Context, query string, page, limit int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("search_users"), time.Now())
+
 	var users []models.User
 	pattern := "%" + query + "%"
 	offset := (page - 1) * limit
 
-	err := r.db.
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).
 		Where("username ILIKE ?", pattern).
 		Order("rating DESC").
 		Limit(limit).
@@ -51,19 +79,24 @@ func (r *UserRepository) SearchUsers(query string, page, limit int) ([]models.Us
 }
 
 // CountSearchUsers counts total users matching the search query
-func (r *UserRepository) CountSearchUsers(query string) (int64, error) {
+func (r *UserRepository) CountSearchUsers(ctx context.Context, query string) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_search_users"), time.Now())
+
 	var count int64
 	pattern := "%" + query + "%"
-	err := r.db.Model(&models.User{}).
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).
 		Where("username ILIKE ?", pattern).
 		Count(&count).Error
 	return count, err
 }
 
-// GetUserByUsername retrieves a single user by username
-func (r *UserRepository) GetUserByUsername(username string) (*models.User, error) {
+// GetUserByUsername retrieves a single user by username, including hidden
+// and banned users
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_user_by_username"), time.Now())
+
 	var user models.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -71,47 +104,71 @@ func (r *UserRepository) GetUserByUsername(username string) (*models.User, error
 }
 
 // GetTotalUsers returns the total count of users in the database
-func (r *UserRepository) GetTotalUsers() (int64, error) {
+func (r *UserRepository) GetTotalUsers(ctx context.Context) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_total_users"), time.Now())
+
 	var count int64
-	err := r.db.Model(&models.User{}).Count(&count).Error
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).Count(&count).Error
 	return count, err
 }
 
 // CountUsersWithHigherRating counts users with rating greater than the given rating
 // Used for rank calculation
-func (r *UserRepository) CountUsersWithHigherRating(rating int) (int64, error) {
+func (r *UserRepository) CountUsersWithHigherRating(ctx context.Context, rating int) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_higher_rating"), time.Now())
+
 	var count int64
-	err := r.db.Model(&models.User{}).Where("rating > ?", rating).Count(&count).Error
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).Where("rating > ?", rating).Count(&count).Error
 	return count, err
 }
 
 // CountUsersWithRating counts users with a specific rating
 // Used for tie-aware ranking
-func (r *UserRepository) CountUsersWithRating(rating int) (int64, error) {
+func (r *UserRepository) CountUsersWithRating(ctx context.Context, rating int) (int64, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("count_with_rating"), time.Now())
+
 	var count int64
-	err := r.db.Model(&models.User{}).Where("rating = ?", rating).Count(&count).Error
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).Where("rating = ?", rating).Count(&count).Error
 	return count, err
 }
 
-func (r *UserRepository) SyncUserToRedis(ctx context.Context, redisRepo *RedisRepository, user models.User) error {
-	return redisRepo.AddToLeaderboard(ctx, user.Username, user.Rating)
-}
-
-func (r *UserRepository) SyncAllUserToRedis(ctx context.Context, redisRepo *RedisRepository) error {
-	// Clear existing Redis data first
-	if err := redisRepo.client.Del(ctx, "leaderboard:ratings").Err(); err != nil {
-		return err
-	}
+// SyncAllUserToRedis rebuilds the Redis leaderboard and profile hash from the
+// database and returns the number of users synced. Both are built under
+// temporary keys and renamed into place together, so readers never see a
+// half-filled leaderboard. The legacy username-keyed sorted set is deleted in
+// the same step, which migrates older deployments on their first sync.
+// The rename overwrites whatever live writes mirrored after the snapshot was
+// read, so callers follow it with CatchUpRedis from before the sync started.
+// It talks to Redis directly, bypassing the circuit breaker, because it is how
+// a recovered Redis is brought back before the circuit closes.
+func (r *UserRepository) SyncAllUserToRedis(ctx context.Context, redisRepo *RedisRepository) (_ int, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.RedisSyncDuration.WithLabelValues(metrics.Result(err)).Observe(time.Since(start).Seconds())
+	}()
 
 	var users []models.User
-	if err := r.db.Order("rating DESC").Find(&users).Error; err != nil {
-		return err
+	if err := r.db.WithContext(ctx).Scopes(rankedUsers).Order("rating DESC").Find(&users).Error; err != nil {
+		return 0, err
 	}
 
 	if len(users) == 0 {
-		return nil
+		if err := redisRepo.client.Del(ctx, leaderboardKey, profilesKey, legacyLeaderboardKey).Err(); err != nil {
+			metrics.RedisWriteFailures.WithLabelValues("sync_all").Inc()
+			return 0, err
+		}
+		return 0, nil
 	}
 
+	// Unique per sync so concurrent syncs from several instances don't mix
+	suffix := fmt.Sprintf(":sync:%d", time.Now().UnixNano())
+	tempKey, tempProfilesKey := leaderboardKey+suffix, profilesKey+suffix
+	defer func() {
+		if err != nil {
+			redisRepo.client.Del(context.WithoutCancel(ctx), tempKey, tempProfilesKey)
+		}
+	}()
+
 	// Batch add to Redis (in chunks for large datasets)
 	batchSize := 1000
 	for i := 0; i < len(users); i += batchSize {
@@ -122,49 +179,291 @@ func (r *UserRepository) SyncAllUserToRedis(ctx context.Context, redisRepo *Redi
 
 		zMembers := make([]redis.Z, 0, end-i)
 		for _, user := range users[i:end] {
-		zMembers = append(zMembers, redis.Z{
-			Score:  float64(user.Rating),
-			Member: user.Username,
+			zMembers = append(zMembers, redis.Z{
+				Score:  float64(user.Rating),
+				Member: Member(user.ID),
+			})
+		}
+		profiles, err := profileValues(users[i:end])
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = redisRepo.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZAdd(ctx, tempKey, zMembers...)
+			pipe.HSet(ctx, tempProfilesKey, profiles...)
+			return nil
 		})
+		if err != nil {
+			metrics.RedisWriteFailures.WithLabelValues("sync_all").Inc()
+			return 0, err
+		}
+	}
+
+	var legacy *redis.IntCmd
+	_, err = redisRepo.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Rename(ctx, tempKey, leaderboardKey)
+		pipe.Rename(ctx, tempProfilesKey, profilesKey)
+		legacy = pipe.Del(ctx, legacyLeaderboardKey)
+		return nil
+	})
+	if err != nil {
+		metrics.RedisWriteFailures.WithLabelValues("sync_all").Inc()
+		return 0, err
 	}
+	if legacy.Val() > 0 {
+		log.Printf("Removed legacy username-keyed leaderboard %s", legacyLeaderboardKey)
+	}
+	return len(users), nil
+}
+
+// catchUpOverlap widens a catch-up window to cover transactions that stamped
+// updated_at before the window opened but committed after, and small clock
+// differences between instances
+const catchUpOverlap = 5 * time.Second
+
+// CatchUpRedis rewrites the Redis entries of every user changed since since,
+// including users who left the board. Rows are read FOR SHARE, so writes in
+// flight commit first and later ones wait until Redis has been written,
+// leaving Redis in commit order. Like SyncAllUserToRedis it bypasses the
+// circuit breaker.
+func (r *UserRepository) CatchUpRedis(ctx context.Context, redisRepo *RedisRepository, since time.Time) error {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("catch_up_redis"), time.Now())
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "SHARE"}).
+			Where("updated_at >= ?", since.Add(-catchUpOverlap)).
+			Find(&users).Error
+		if err != nil || len(users) == 0 {
+			return err
+		}
+
+		ranked := make([]models.User, 0, len(users))
+		for _, user := range users {
+			if user.Ranked() {
+				ranked = append(ranked, user)
+			}
+		}
+		profiles, err := profileValues(ranked)
+		if err != nil {
+			return err
+		}
+
+		_, err = redisRepo.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, user := range users {
+				if user.Ranked() {
+					pipe.ZAdd(ctx, leaderboardKey, redis.Z{Score: float64(user.Rating), Member: Member(user.ID)})
+				} else {
+					pipe.ZRem(ctx, leaderboardKey, Member(user.ID))
+					pipe.HDel(ctx, profilesKey, Member(user.ID))
+				}
+			}
+			if len(profiles) > 0 {
+				pipe.HSet(ctx, profilesKey, profiles...)
+			}
+			return nil
+		})
+		if err != nil {
+			metrics.RedisWriteFailures.WithLabelValues("catch_up").Inc()
+		}
+		return err
+	})
+}
+
+// GetUsersByIDs returns the users with the given IDs, in no particular order
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_users_by_ids"), time.Now())
+
+	var users []models.User
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// RateFunc picks the new rating for a locked user. Returning false leaves the
+// user unchanged.
+type RateFunc func(user models.User) (newRating int, ok bool)
+
+// RatingWrite describes the cause of a rating change for rating_history
+type RatingWrite struct {
+	// Source is one of the models.RatingSource constants
+	Source string
+	// MatchID links the change to a match
+	MatchID *int
+	// Reason explains a manual change
+	Reason *string
+}
+
+// UpdateUserRating locks the user, asks rate for their new rating and writes
+// it, raising their peak rating if it is a new best, and records the change in
+// rating_history with the write's source, match and reason. Changes that
+// count as activity also move LastActiveAt and bring back a user hidden for
+// inactivity. mirror runs before the commit. It returns the user before and
+// after the change; after is nil when rate declined.
+func (r *UserRepository) UpdateUserRating(ctx context.Context, username string, write RatingWrite, rate RateFunc, mirror MirrorFunc) (before, after *models.User, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("update_user_rating"), time.Now())
 
-		if err := redisRepo.client.ZAdd(ctx, "leaderboard:ratings", zMembers...).Err(); err != nil {
+	var rangeErr error
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := lockUser(tx, username)
+		if err != nil {
 			return err
 		}
+		before = user
+
+		newRating, ok := rate(*user)
+		if !ok {
+			return nil
+		}
+		if newRating < models.MinRating || newRating > models.MaxRating {
+			rangeErr = fmt.Errorf("rating must be between %d and %d", models.MinRating, models.MaxRating)
+			return rangeErr
+		}
+
+		now := time.Now()
+		updated := *user
+		updated.Rating = newRating
+		updated.RaisePeakRating(now)
+		if models.CountsAsActivity(write.Source) {
+			updated.LastActiveAt = now
+			if updated.HiddenFor(models.HiddenInactive) {
+				updated.HiddenAt, updated.HiddenReason = nil, nil
+			}
+		}
+		err = tx.Model(&models.User{}).
+			Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"rating":         updated.Rating,
+				"peak_rating":    updated.PeakRating,
+				"peak_rating_at": updated.PeakRatingAt,
+				"last_active_at": updated.LastActiveAt,
+				"hidden_at":      updated.HiddenAt,
+				"hidden_reason":  updated.HiddenReason,
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&models.RatingHistory{
+			UserID:    user.ID,
+			OldRating: user.Rating,
+			NewRating: newRating,
+			Source:    write.Source,
+			MatchID:   write.MatchID,
+			Reason:    write.Reason,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		after = &updated
+		mirror(*user, updated)
+		return nil
+	})
+	if err != nil {
+		if rangeErr == nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			metrics.DBWriteFailures.WithLabelValues("update_user_rating").Inc()
+		}
+		return nil, nil, err
 	}
 
-	return nil
+	return before, after, nil
 }
 
-// UpdateUserRating updates a user's rating in the database
-func (r *UserRepository) UpdateUserRating(ctx context.Context, username string, newRating int) error {
-	// Validate rating range
-	if newRating < 100 || newRating > 5000 {
-		return fmt.Errorf("rating must be between 100 and 5000")
+// CreateUser inserts a new user, returning ErrUsernameTaken if the username
+// is in use, and mirrors it before committing
+func (r *UserRepository) CreateUser(ctx context.Context, user *models.User, mirror MirrorFunc) error {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("create_user"), time.Now())
+
+	user.RaisePeakRating(time.Now())
+	user.LastActiveAt = time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		mirror(models.User{}, *user)
+		return nil
+	})
+	if isUniqueViolation(err) {
+		return ErrUsernameTaken
 	}
+	if err != nil {
+		metrics.DBWriteFailures.WithLabelValues("create_user").Inc()
+	}
+	return err
+}
 
-	result := r.db.WithContext(ctx).
-		Model(&models.User{}).
-		Where("username = ?", username).
-		Update("rating", newRating)
+// ModifyUser locks the user's row and calls modify on it. If modify reports a
+// change, the user is saved and mirrored before committing. It returns the
+// user as stored, and ErrUsernameTaken if a rename collides.
+func (r *UserRepository) ModifyUser(ctx context.Context, username string, modify func(user *models.User) (bool, error), mirror MirrorFunc) (*models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("modify_user"), time.Now())
 
-	if result.Error != nil {
-		return result.Error
+	var after *models.User
+	var modifyErr error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := lockUser(tx, username)
+		if err != nil {
+			return err
+		}
+		before := *user
+		changed, err := modify(user)
+		if err != nil {
+			modifyErr = err
+			return err
+		}
+		if !changed {
+			after = user
+			return nil
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		after = user
+		mirror(before, *user)
+		return nil
+	})
+	if isUniqueViolation(err) {
+		return nil, ErrUsernameTaken
 	}
+	if err != nil {
+		if modifyErr == nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			metrics.DBWriteFailures.WithLabelValues("modify_user").Inc()
+		}
+		return nil, err
+	}
+	return after, nil
+}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("user not found: %s", username)
+// lockUser loads a user and locks their row until the transaction ends
+func lockUser(tx *gorm.DB, username string) (*models.User, error) {
+	var user models.User
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("username = ?", username).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %s: %w", username, gorm.ErrRecordNotFound)
+		}
+		return nil, err
 	}
+	return &user, nil
+}
 
-	return nil
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
 }
 
 // GetRandomUsers retrieves random users for simulation
 func (r *UserRepository) GetRandomUsers(ctx context.Context, count int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("get_random_users"), time.Now())
+
 	var users []models.User
 
 	// Use PostgreSQL's TABLESAMPLE for efficient random sampling
-	err := r.db.WithContext(ctx).
+	err := r.db.WithContext(ctx).Scopes(rankedUsers).
 		Table("users").
 		Order("RANDOM()").
 		Limit(count).
@@ -172,3 +471,35 @@ func (r *UserRepository) GetRandomUsers(ctx context.Context, count int) ([]model
 
 	return users, err
 }
+
+// RatingAtPosition returns the rating of the ranked user at the given
+// position, counting from 1 with ties in any order. found is false when
+// fewer users are ranked.
+func (r *UserRepository) RatingAtPosition(ctx context.Context, position int64) (rating int, found bool, err error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("rating_at_position"), time.Now())
+
+	var ratings []int
+	err = r.db.WithContext(ctx).Scopes(rankedUsers).Model(&models.User{}).
+		Order("rating DESC").Offset(int(position-1)).Limit(1).
+		Pluck("rating", &ratings).Error
+	if err != nil || len(ratings) == 0 {
+		return 0, false, err
+	}
+	return ratings[0], true, nil
+}
+
+// ListUsersByRating returns up to limit users in leaderboard order (rating DESC,
+// then id) that come after the given user, or from the top when after is nil.
+// Keyset pagination keeps full scans cheap at any depth.
+func (r *UserRepository) ListUsersByRating(ctx context.Context, after *models.User, limit int) ([]models.User, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("list_users_by_rating"), time.Now())
+
+	query := r.db.WithContext(ctx).Scopes(rankedUsers).Order("rating DESC, id ASC").Limit(limit)
+	if after != nil {
+		query = query.Where("rating < ? OR (rating = ? AND id > ?)", after.Rating, after.Rating, after.ID)
+	}
+
+	var users []models.User
+	err := query.Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotRepository stores periodic copies of the top of the leaderboard
+type SnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotRepository creates a new SnapshotRepository instance
+func NewSnapshotRepository(db *gorm.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// TakeSnapshot copies the top size ranked users, with tie-aware ranks, into a
+// new snapshot in one transaction
+func (r *SnapshotRepository) TakeSnapshot(ctx context.Context, size int) (*models.LeaderboardSnapshot, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("take_snapshot"), time.Now())
+
+	snapshot := &models.LeaderboardSnapshot{TakenAt: time.Now()}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Scopes(rankedUsers).Count(&snapshot.TotalUsers).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(snapshot).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO leaderboard_snapshot_entries (snapshot_id, rank, user_id, username, rating)
+			SELECT ?, RANK() OVER (ORDER BY rating DESC), id, username, rating
+			FROM users
+			WHERE `+rankedUserCondition+`
+			ORDER BY rating DESC, id ASC
+			LIMIT ?`, snapshot.ID, size).Error
+	})
+	if err != nil {
+		metrics.DBWriteFailures.WithLabelValues("take_snapshot").Inc()
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// DeleteSnapshotsBefore removes snapshots taken before cutoff, and their
+// entries, returning how many were removed
+func (r *SnapshotRepository) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("taken_at < ?", cutoff).Delete(&models.LeaderboardSnapshot{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository stores webhooks and their delivery log, which doubles as
+// the retry queue
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository instance
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create stores a new webhook
+func (r *WebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// Get returns one webhook
+func (r *WebhookRepository) Get(ctx context.Context, id int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List returns every webhook, oldest first
+func (r *WebhookRepository) List(ctx context.Context) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetByIDs returns the webhooks with the given IDs, keyed by ID
+func (r *WebhookRepository) GetByIDs(ctx context.Context, ids []int) (map[int]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		byID[webhook.ID] = webhook
+	}
+	return byID, nil
+}
+
+// Delete removes a webhook and its delivery log, returning
+// gorm.ErrRecordNotFound if there is no such webhook
+func (r *WebhookRepository) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Webhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Enqueue stores deliveries to be sent as soon as a dispatcher claims them
+func (r *WebhookRepository) Enqueue(ctx context.Context, deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("enqueue_webhook_deliveries"), time.Now())
+
+	if err := r.db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		metrics.DBWriteFailures.WithLabelValues("enqueue_webhook_deliveries").Inc()
+		return err
+	}
+	return nil
+}
+
+// ClaimDue takes up to limit pending deliveries whose next attempt is due and
+// pushes their next attempt lease into the future, so no other dispatcher
+// sends them meanwhile. A dispatcher that dies mid-send leaves them to be
+// claimed again once the lease runs out.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	defer metrics.ObserveSince(metrics.DBQueryDuration.WithLabelValues("claim_webhook_deliveries"), time.Now())
+
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)
+		RETURNING *`, time.Now().Add(lease), models.DeliveryPending, limit).
+		Scan(&deliveries).Error
+	return deliveries, err
+}
+
+// SaveAttempt records the outcome of a delivery attempt
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "delivered_at").
+		Updates(delivery).Error
+}
+
+// ListDeliveries returns a webhook's latest deliveries, newest first, only
+// those with status when it is set
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	db := r.db.WithContext(ctx).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	var deliveries []models.WebhookDelivery
+	err := db.Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// DeleteDeliveriesBefore removes finished deliveries created before cutoff,
+// returning how many were removed. Deliveries still being retried are kept.
+func (r *WebhookRepository) DeleteDeliveriesBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ? AND status <> ?", cutoff, models.DeliveryPending).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
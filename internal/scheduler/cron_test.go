@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func at(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	// 2025-01-01 is a Wednesday
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"step", "*/15 * * * *", "2025-01-01T10:07:00Z", "2025-01-01T10:15:00Z"},
+		{"step wraps hour", "*/15 * * * *", "2025-01-01T10:45:00Z", "2025-01-01T11:00:00Z"},
+		{"strictly after", "*/15 * * * *", "2025-01-01T10:15:00Z", "2025-01-01T10:30:00Z"},
+		{"seconds are dropped", "*/15 * * * *", "2025-01-01T10:14:59Z", "2025-01-01T10:15:00Z"},
+		{"step from offset", "5/15 * * * *", "2025-01-01T10:07:00Z", "2025-01-01T10:20:00Z"},
+		{"step from offset wraps", "5/15 * * * *", "2025-01-01T10:50:00Z", "2025-01-01T11:05:00Z"},
+		{"range", "0 9-17 * * *", "2025-01-01T08:59:00Z", "2025-01-01T09:00:00Z"},
+		{"range wraps day", "0 9-17 * * *", "2025-01-01T17:30:00Z", "2025-01-02T09:00:00Z"},
+		{"range with step", "10-20/5 * * * *", "2025-01-01T10:16:00Z", "2025-01-01T10:20:00Z"},
+		{"range with step wraps", "10-20/5 * * * *", "2025-01-01T10:21:00Z", "2025-01-01T11:10:00Z"},
+		{"list", "0 6,18 * * *", "2025-01-01T07:00:00Z", "2025-01-01T18:00:00Z"},
+		{"weekdays", "0 0 * * 1-5", "2025-01-03T12:00:00Z", "2025-01-06T00:00:00Z"},
+		{"dow 0 is sunday", "0 0 * * 0", "2025-01-01T00:00:00Z", "2025-01-05T00:00:00Z"},
+		{"dow 7 is sunday", "0 0 * * 7", "2025-01-01T00:00:00Z", "2025-01-05T00:00:00Z"},
+		{"dom only", "0 0 13 * *", "2025-01-01T00:00:00Z", "2025-01-13T00:00:00Z"},
+		{"dom or dow matches dow", "0 0 13 * 5", "2025-01-01T00:00:00Z", "2025-01-03T00:00:00Z"},
+		{"dom or dow matches dom", "0 0 13 * 5", "2025-01-10T00:00:00Z", "2025-01-13T00:00:00Z"},
+		{"month", "0 0 1 3 *", "2025-01-15T00:00:00Z", "2025-03-01T00:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2025-01-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"never", "0 0 31 2 *", "2025-01-01T00:00:00Z", ""},
+		{"daily", "@daily", "2025-01-01T10:00:00Z", "2025-01-02T00:00:00Z"},
+		{"weekly", "@weekly", "2025-01-01T10:00:00Z", "2025-01-05T00:00:00Z"},
+		{"hourly", "@hourly", "2025-01-01T10:00:00Z", "2025-01-01T11:00:00Z"},
+		{"every aligned", "@every 15m", "2025-01-01T10:07:30Z", "2025-01-01T10:15:00Z"},
+		{"every strictly after", "@every 15m", "2025-01-01T10:15:00Z", "2025-01-01T10:30:00Z"},
+		{"every hour", "@every 1h", "2025-01-01T10:59:59Z", "2025-01-01T11:00:00Z"},
+		{"every across zones", "@every 1h", "2025-01-01T16:29:00+05:30", "2025-01-01T11:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.expr, err)
+			}
+			got := schedule.Next(at(tt.from))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want none", tt.from, got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"5-1 * * * *",
+		"1- * * * *",
+		"@fortnightly",
+		"@every",
+		"@every x",
+		"@every 500ms",
+		"@every -1m",
+	}
+	for _, expr := range tests {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", expr)
+		}
+	}
+}
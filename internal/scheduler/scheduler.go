@@ -0,0 +1,385 @@
+// Package scheduler runs named jobs on cron schedules. Every instance runs
+// the same schedules; a Redis lock makes sure only one of them executes each
+// activation. Paused state and run history live in Postgres so they are shared
+// by every instance and survive restarts.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultTimeout bounds jobs registered without a timeout
+const DefaultTimeout = 10 * time.Minute
+
+const (
+	// lockMargin keeps a job's lock alive a little past its timeout so a run
+	// that overshoots while shutting down is not joined by another
+	lockMargin = 30 * time.Second
+	// claimTTL is how long an activation stays claimed. It only has to
+	// outlast clock skew between instances.
+	claimTTL = 10 * time.Minute
+)
+
+// Job is a named unit of work run on a schedule
+type Job struct {
+	Name string
+	// Schedule is a cron expression; see ParseSchedule
+	Schedule string
+	// Timeout bounds a single run; DefaultTimeout if zero
+	Timeout time.Duration
+	// Paused is the state the job starts in the first time it is registered.
+	// After that the state set through the admin API wins.
+	Paused bool
+	// Run does the work and returns a short summary for the run history
+	Run func(ctx context.Context) (summary string, err error)
+}
+
+// entry is a registered job
+type entry struct {
+	job      Job
+	schedule Schedule
+	// running keeps a job from overlapping itself on this instance, with or
+	// without Redis
+	running sync.Mutex
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (e *entry) setNext(t time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.next = t
+}
+
+func (e *entry) nextRun() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.next
+}
+
+// Scheduler runs registered jobs on their schedules
+type Scheduler struct {
+	jobRepo *repository.JobRepository
+	// redisRepo is nil without Redis, in which case this instance is assumed
+	// to be the only one and jobs run without a distributed lock
+	redisRepo *repository.RedisRepository
+	instance  string
+
+	jobs  map[string]*entry
+	names []string
+}
+
+// New creates a Scheduler. Jobs must be registered before Start.
+func New(jobRepo *repository.JobRepository, redisRepo *repository.RedisRepository) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return &Scheduler{
+		jobRepo:   jobRepo,
+		redisRepo: redisRepo,
+		instance:  fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		jobs:      make(map[string]*entry),
+	}
+}
+
+// Register adds a job and creates its state row if this is the first time
+// any instance has registered it
+func (s *Scheduler) Register(ctx context.Context, job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a run function")
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+	if err := s.jobRepo.EnsureJob(ctx, job.Name, job.Paused); err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.jobs[job.Name] = &entry{job: job, schedule: schedule}
+	s.names = append(s.names, job.Name)
+	return nil
+}
+
+// Start runs every registered job on its schedule until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, name := range s.names {
+		go s.loop(ctx, s.jobs[name])
+	}
+	log.Printf("Scheduler started on %s with %d jobs", s.instance, len(s.names))
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Job %s has no future activations", e.job.Name)
+			return
+		}
+		e.setNext(next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.fire(ctx, e, next)
+	}
+}
+
+// fire runs one scheduled activation unless the job is paused, still running
+// here, or claimed by another instance
+func (s *Scheduler) fire(ctx context.Context, e *entry, at time.Time) {
+	name := e.job.Name
+	state, err := s.jobRepo.GetJob(ctx, name)
+	if err != nil {
+		log.Printf("Job %s skipped: failed to read its state: %v", name, err)
+		return
+	}
+	if state.Paused {
+		return
+	}
+
+	// Every instance fires at the same minute; the first to claim the
+	// activation runs it. The claim outlives the run so an instance whose
+	// clock lags cannot pick the same activation up after the lock is freed.
+	if s.redisRepo != nil {
+		claimed, err := s.redisRepo.AcquireLock(ctx, fmt.Sprintf("scheduler:claim:%s:%d", name, at.Unix()), s.instance, claimTTL)
+		if err != nil {
+			metrics.JobRuns.WithLabelValues(name, "lock_unavailable").Inc()
+			log.Printf("Job %s skipped: failed to take its lock: %v", name, err)
+			return
+		}
+		if !claimed {
+			metrics.JobRuns.WithLabelValues(name, "locked").Inc()
+			return
+		}
+	}
+
+	run, err := s.begin(ctx, e, models.JobTriggerSchedule)
+	if err != nil {
+		if appErr := apperrors.As(err); appErr != nil && appErr.Kind == apperrors.KindConflict {
+			metrics.JobRuns.WithLabelValues(name, "locked").Inc()
+			log.Printf("Job %s skipped: previous run still in progress", name)
+			return
+		}
+		log.Printf("Job %s skipped: %v", name, err)
+		return
+	}
+	s.execute(e, run)
+}
+
+// begin takes the job's locks and records the start of a run. On success the
+// caller must hand the run to execute, which releases the locks.
+func (s *Scheduler) begin(ctx context.Context, e *entry, trigger string) (*models.JobRun, error) {
+	name := e.job.Name
+	if !e.running.TryLock() {
+		return nil, apperrors.Conflict("job %s is already running", name).WithDetail("job", name)
+	}
+
+	if s.redisRepo != nil {
+		acquired, err := s.redisRepo.AcquireLock(ctx, lockKey(name), s.instance, e.job.Timeout+lockMargin)
+		if err != nil {
+			e.running.Unlock()
+			return nil, apperrors.Unavailable("cannot lock job %s: Redis is unavailable", name).Wrap(err)
+		}
+		if !acquired {
+			e.running.Unlock()
+			return nil, apperrors.Conflict("job %s is running on another instance", name).WithDetail("job", name)
+		}
+	}
+
+	run := &models.JobRun{
+		Job:       name,
+		Trigger:   trigger,
+		Instance:  s.instance,
+		Status:    models.JobRunning,
+		StartedAt: time.Now(),
+	}
+	if err := s.jobRepo.CreateRun(ctx, run); err != nil {
+		s.unlock(e)
+		return nil, err
+	}
+	return run, nil
+}
+
+// execute runs the job, records the outcome and releases its locks. It is
+// detached from the caller's context; only the job timeout bounds it.
+func (s *Scheduler) execute(e *entry, run *models.JobRun) {
+	defer s.unlock(e)
+	name := e.job.Name
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.job.Timeout)
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "Scheduler.Run", trace.WithAttributes(
+		attribute.String("job.name", name),
+		attribute.String("job.trigger", run.Trigger),
+		attribute.Int("job.run_id", run.ID),
+	))
+
+	summary, err := s.call(ctx, e)
+	tracing.End(span, err)
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Status = models.JobSucceeded
+	if summary != "" {
+		run.Summary = &summary
+	}
+	if err != nil {
+		msg := err.Error()
+		run.Status, run.Error = models.JobFailed, &msg
+	}
+	if err := s.jobRepo.SaveRun(context.Background(), run); err != nil {
+		log.Printf("Failed to record run %d of job %s: %v", run.ID, name, err)
+	}
+
+	metrics.JobRuns.WithLabelValues(name, run.Status).Inc()
+	metrics.ObserveSince(metrics.JobDuration.WithLabelValues(name), run.StartedAt)
+	if err != nil {
+		log.Printf("Job %s run %d failed after %v: %v", name, run.ID, finished.Sub(run.StartedAt), err)
+		return
+	}
+	log.Printf("Job %s run %d succeeded in %v: %s", name, run.ID, finished.Sub(run.StartedAt), summary)
+}
+
+// call runs the job function, turning a panic into an error so one bad run
+// does not take the process down
+func (s *Scheduler) call(ctx context.Context, e *entry) (summary string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return e.job.Run(ctx)
+}
+
+func (s *Scheduler) unlock(e *entry) {
+	if s.redisRepo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// If this fails the lock expires on its own
+		if err := s.redisRepo.ReleaseLock(ctx, lockKey(e.job.Name), s.instance); err != nil {
+			log.Printf("Failed to release lock for job %s: %v", e.job.Name, err)
+		}
+	}
+	e.running.Unlock()
+}
+
+func lockKey(name string) string {
+	return "scheduler:lock:" + name
+}
+
+// Jobs describes every registered job in registration order
+func (s *Scheduler) Jobs(ctx context.Context) ([]models.JobInfo, error) {
+	states, err := s.jobRepo.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns, err := s.jobRepo.LastRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]models.JobInfo, 0, len(s.names))
+	for _, name := range s.names {
+		info := s.info(s.jobs[name], states[name].Paused)
+		if run, ok := lastRuns[name]; ok {
+			info.LastRun = &run
+		}
+		jobs = append(jobs, info)
+	}
+	return jobs, nil
+}
+
+func (s *Scheduler) info(e *entry, paused bool) models.JobInfo {
+	info := models.JobInfo{Name: e.job.Name, Schedule: e.job.Schedule, Paused: paused}
+	if next := e.nextRun(); !paused && !next.IsZero() {
+		info.NextRun = &next
+	}
+	return info
+}
+
+// Trigger starts a run of the job now, paused or not, and returns it without
+// waiting for it to finish. It fails with a conflict if the job is already
+// running anywhere.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*models.JobRun, error) {
+	e, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.begin(ctx, e, models.JobTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	audit.SetChange(ctx, nil, started)
+	go s.execute(e, run)
+	return &started, nil
+}
+
+// SetPaused pauses or resumes a job on every instance. A run in progress is
+// not interrupted.
+func (s *Scheduler) SetPaused(ctx context.Context, name string, paused bool) (*models.JobInfo, error) {
+	e, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	before, err := s.jobRepo.GetJob(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.jobRepo.SetPaused(ctx, name, paused)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetChange(ctx, before, after)
+	log.Printf("Job %s paused=%t", name, paused)
+	info := s.info(e, paused)
+	return &info, nil
+}
+
+// Runs returns the most recent runs of a job, newest first
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]models.JobRun, error) {
+	if _, err := s.lookup(name); err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+	return s.jobRepo.ListRuns(ctx, name, limit)
+}
+
+func (s *Scheduler) lookup(name string) (*entry, error) {
+	e, ok := s.jobs[name]
+	if !ok {
+		return nil, apperrors.NotFound("job %q not found", name).WithDetail("job", name)
+	}
+	return e, nil
+}
@@ -0,0 +1,291 @@
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+)
+
+// Rating distributions
+const (
+	// DistributionTiered picks a tier by weight, then a rating uniformly
+	// within it; see DefaultTiers
+	DistributionTiered = "tiered"
+	// DistributionUniform spreads ratings evenly over the allowed range
+	DistributionUniform = "uniform"
+	// DistributionNormal centres ratings around Options.Mean
+	DistributionNormal = "normal"
+	// DistributionPowerLaw puts most users near the bottom with a long tail
+	// towards the top, shaped by Options.Alpha
+	DistributionPowerLaw = "powerlaw"
+)
+
+// Distributions lists the supported rating distributions
+var Distributions = []string{DistributionTiered, DistributionUniform, DistributionNormal, DistributionPowerLaw}
+
+// Tier is a rating band chosen with probability proportional to Weight
+type Tier struct {
+	Min    int
+	Max    int
+	Weight float64
+}
+
+// DefaultTiers mimics a real leaderboard: few users at the top, most in the middle
+var DefaultTiers = []Tier{
+	{Min: 4500, Max: 5000, Weight: 10},
+	{Min: 3500, Max: 4499, Weight: 20},
+	{Min: 2500, Max: 3499, Weight: 30},
+	{Min: 1500, Max: 2499, Weight: 30},
+	{Min: 100, Max: 1499, Weight: 10},
+}
+
+// ParseTiers parses "min-max:weight,..." e.g. "4500-5000:10,100-4499:90"
+func ParseTiers(s string) ([]Tier, error) {
+	var tiers []Tier
+	for _, part := range strings.Split(s, ",") {
+		bounds, weightStr, ok := strings.Cut(strings.TrimSpace(part), ":")
+		minStr, maxStr, ok2 := strings.Cut(bounds, "-")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("tier %q must look like min-max:weight", part)
+		}
+		lo, errMin := strconv.Atoi(minStr)
+		hi, errMax := strconv.Atoi(maxStr)
+		weight, errWeight := strconv.ParseFloat(weightStr, 64)
+		if errMin != nil || errMax != nil || errWeight != nil {
+			return nil, fmt.Errorf("tier %q must look like min-max:weight", part)
+		}
+		tiers = append(tiers, Tier{Min: lo, Max: hi, Weight: weight})
+	}
+	return tiers, validateTiers(tiers)
+}
+
+func validateTiers(tiers []Tier) error {
+	if len(tiers) == 0 {
+		return fmt.Errorf("at least one tier is required")
+	}
+	for _, t := range tiers {
+		if t.Min < models.MinRating || t.Max > models.MaxRating || t.Min > t.Max {
+			return fmt.Errorf("tier %d-%d must lie within %d-%d", t.Min, t.Max, models.MinRating, models.MaxRating)
+		}
+		if t.Weight <= 0 {
+			return fmt.Errorf("tier %d-%d needs a positive weight", t.Min, t.Max)
+		}
+	}
+	return nil
+}
+
+// Dataset is everything Generate produces. Users have IDs 1..len(Users) and
+// matches IDs 1..len(Matches) in the order they were played. Users' peak
+// ratings are the best they held over the generated history.
+type Dataset struct {
+	Users   []models.User
+	Matches []MatchRecord
+}
+
+// MatchRecord is a generated match and the rating change it caused for both
+// players. Winner is 0 for a draw. Each record becomes one matches row and
+// two rating_history rows.
+type MatchRecord struct {
+	ID           int
+	PlayerOne    int
+	PlayerTwo    int
+	Winner       int
+	PlayerOneOld int
+	PlayerOneNew int
+	PlayerTwoOld int
+	PlayerTwoNew int
+	PlayedAt     time.Time
+}
+
+const (
+	// eloK is the K-factor used to size rating changes
+	eloK = 32
+	// drawRate is the share of matches that end in a draw
+	drawRate = 0.1
+	// tieStep is the rating grid tied users are snapped to
+	tieStep = 500
+)
+
+// Generate builds the dataset described by opts. It does not touch the
+// database, so tests can use it directly. The same options always produce
+// the same dataset.
+func Generate(opts Options) *Dataset {
+	rng := rand.New(rand.NewSource(opts.Seed))
+	span := time.Duration(opts.HistoryDays) * 24 * time.Hour
+	start := opts.EndTime.Add(-span)
+
+	users := make([]models.User, 0, opts.Count)
+	taken := make(map[string]bool, opts.Count)
+	for i := 0; i < opts.Count; i++ {
+		// Accounts were created up to 30 days before the history window
+		createdAt := start.Add(-time.Duration(rng.Int63n(int64(30 * 24 * time.Hour))))
+		users = append(users, models.User{
+			ID:        i + 1,
+			Username:  uniqueUsername(rng, taken, i),
+			Rating:    rating(rng, opts),
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+	}
+
+	matches := generateMatches(rng, users, opts, start, span)
+	setPeaks(users, matches)
+	return &Dataset{Users: users, Matches: matches}
+}
+
+// setPeaks sets each user's peak rating to the best rating they held: the one
+// they started with, then each match result in the order played
+func setPeaks(users []models.User, matches []MatchRecord) {
+	started := make([]bool, len(users))
+	raise := func(id, rating int, at time.Time) {
+		user := &users[id-1]
+		if rating > user.PeakRating {
+			user.PeakRating, user.PeakRatingAt = rating, &at
+		}
+	}
+	for _, m := range matches {
+		for _, p := range [][3]int{{m.PlayerOne, m.PlayerOneOld, m.PlayerOneNew}, {m.PlayerTwo, m.PlayerTwoOld, m.PlayerTwoNew}} {
+			if !started[p[0]-1] {
+				started[p[0]-1] = true
+				raise(p[0], p[1], users[p[0]-1].CreatedAt)
+			}
+			raise(p[0], p[2], m.PlayedAt)
+		}
+	}
+	for i, user := range users {
+		if !started[i] {
+			raise(user.ID, user.Rating, user.CreatedAt)
+		}
+	}
+}
+
+// generateMatches plays matches backwards from the final ratings: each match
+// undoes an Elo-sized change, so history ends exactly at the generated
+// ratings whatever their distribution. Users' UpdatedAt is set to their last match.
+func generateMatches(rng *rand.Rand, users []models.User, opts Options, start time.Time, span time.Duration) []MatchRecord {
+	total := len(users) * opts.MatchesPerUser / 2
+	if total == 0 || len(users) < 2 || span <= 0 {
+		return nil
+	}
+
+	// Latest first, since matches are generated backwards
+	playedAt := make([]time.Time, total)
+	for i := range playedAt {
+		playedAt[i] = start.Add(time.Duration(rng.Int63n(int64(span))))
+	}
+	sort.Slice(playedAt, func(i, j int) bool { return playedAt[i].After(playedAt[j]) })
+
+	current := make([]int, len(users))
+	for i, u := range users {
+		current[i] = u.Rating
+	}
+
+	matches := make([]MatchRecord, total)
+	for k := 0; k < total; k++ {
+		a := rng.Intn(len(users))
+		b := rng.Intn(len(users) - 1)
+		if b >= a {
+			b++
+		}
+
+		expectedA := 1 / (1 + math.Pow(10, float64(current[b]-current[a])/400))
+		scoreA, winner := 0.5, 0
+		switch roll := rng.Float64(); {
+		case roll < drawRate:
+		case rng.Float64() < expectedA:
+			scoreA, winner = 1, users[a].ID
+		default:
+			scoreA, winner = 0, users[b].ID
+		}
+		delta := int(math.Round(eloK * (scoreA - expectedA)))
+
+		m := MatchRecord{
+			PlayerOne:    users[a].ID,
+			PlayerTwo:    users[b].ID,
+			Winner:       winner,
+			PlayerOneNew: current[a],
+			PlayerOneOld: clampRating(current[a] - delta),
+			PlayerTwoNew: current[b],
+			PlayerTwoOld: clampRating(current[b] + delta),
+			PlayedAt:     playedAt[k],
+		}
+		for _, i := range []int{a, b} {
+			if users[i].UpdatedAt.Before(m.PlayedAt) {
+				users[i].UpdatedAt = m.PlayedAt
+			}
+		}
+		current[a], current[b] = m.PlayerOneOld, m.PlayerTwoOld
+		// Fill from the end so the slice ends up in the order matches were played
+		matches[total-1-k] = m
+	}
+
+	for i := range matches {
+		matches[i].ID = i + 1
+	}
+	return matches
+}
+
+// uniqueUsername picks a username pattern for the index-th user, adding
+// random suffixes until it is unused
+func uniqueUsername(rng *rand.Rand, taken map[string]bool, index int) string {
+	patterns := []string{"user_%d", "player_%d", "gamer_%d", "user%d", "player%d"}
+	username := fmt.Sprintf(patterns[rng.Intn(len(patterns))], index+1)
+
+	// Some variation with a random suffix
+	if rng.Float32() < 0.3 {
+		username = fmt.Sprintf("%s_%d", username, rng.Intn(1000))
+	}
+	for taken[username] {
+		username = fmt.Sprintf("%s_%d", username, rng.Intn(10000))
+	}
+	taken[username] = true
+	return username
+}
+
+// rating draws one final rating. With probability TieDensity it is snapped
+// to a multiple of tieStep, so that share of users tie with many others.
+func rating(rng *rand.Rand, opts Options) int {
+	var r int
+	switch opts.Distribution {
+	case DistributionUniform:
+		r = models.MinRating + rng.Intn(models.MaxRating-models.MinRating+1)
+	case DistributionNormal:
+		r = int(math.Round(rng.NormFloat64()*opts.StdDev + opts.Mean))
+	case DistributionPowerLaw:
+		// Pareto tail above MinRating; most users within a few hundred points of it
+		r = models.MinRating + int(300*(math.Pow(1-rng.Float64(), -1/opts.Alpha)-1))
+	default:
+		r = tieredRating(rng, opts.Tiers)
+	}
+
+	if rng.Float64() < opts.TieDensity {
+		r = int(math.Round(float64(r)/tieStep)) * tieStep
+	}
+	return clampRating(r)
+}
+
+func tieredRating(rng *rand.Rand, tiers []Tier) int {
+	total := 0.0
+	for _, t := range tiers {
+		total += t.Weight
+	}
+	pick := rng.Float64() * total
+	for _, t := range tiers {
+		if pick < t.Weight {
+			return t.Min + rng.Intn(t.Max-t.Min+1)
+		}
+		pick -= t.Weight
+	}
+	last := tiers[len(tiers)-1]
+	return last.Min + rng.Intn(last.Max-last.Min+1)
+}
+
+func clampRating(r int) int {
+	return min(max(r, models.MinRating), models.MaxRating)
+}
@@ -0,0 +1,197 @@
+package seed
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+)
+
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.Count = 2000
+	opts.MatchesPerUser = 4
+	opts.EndTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts.Seed = 42
+	return opts
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	opts := testOptions()
+	first, second := Generate(opts), Generate(opts)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("the same options generated different datasets")
+	}
+
+	opts.Seed++
+	if reflect.DeepEqual(first.Users, Generate(opts).Users) {
+		t.Fatal("a different seed generated the same users")
+	}
+}
+
+func TestGenerateRatingBounds(t *testing.T) {
+	tiers := []Tier{{Min: 1000, Max: 1200, Weight: 3}, {Min: 3000, Max: 3100, Weight: 1}}
+	tests := []struct {
+		name  string
+		opts  func(*Options)
+		check func(t *testing.T, ratings []int)
+	}{
+		{
+			name: "tiered",
+			opts: func(o *Options) { o.Distribution, o.Tiers, o.TieDensity = DistributionTiered, tiers, 0 },
+			check: func(t *testing.T, ratings []int) {
+				low := 0
+				for _, r := range ratings {
+					switch {
+					case r >= 1000 && r <= 1200:
+						low++
+					case r >= 3000 && r <= 3100:
+					default:
+						t.Fatalf("rating %d is outside every tier", r)
+					}
+				}
+				// Tiers are picked by weight, 3:1 here
+				if share := float64(low) / float64(len(ratings)); share < 0.7 || share > 0.8 {
+					t.Errorf("%.2f of ratings in the first tier, want about 0.75", share)
+				}
+			},
+		},
+		{
+			name: "uniform",
+			opts: func(o *Options) { o.Distribution, o.TieDensity = DistributionUniform, 0 },
+			check: func(t *testing.T, ratings []int) {
+				mid := (models.MinRating + models.MaxRating) / 2
+				if m := median(ratings); m < mid-200 || m > mid+200 {
+					t.Errorf("median %d, want about %d", m, mid)
+				}
+			},
+		},
+		{
+			name: "normal",
+			opts: func(o *Options) { o.Distribution, o.Mean, o.StdDev, o.TieDensity = DistributionNormal, 2000, 100, 0 },
+			check: func(t *testing.T, ratings []int) {
+				if m := median(ratings); m < 1950 || m > 2050 {
+					t.Errorf("median %d, want about 2000", m)
+				}
+				for _, r := range ratings {
+					// Over six standard deviations out is not a normal draw
+					if r < 1400 || r > 2600 {
+						t.Fatalf("rating %d is too far from the mean", r)
+					}
+				}
+			},
+		},
+		{
+			name: "power law",
+			opts: func(o *Options) { o.Distribution, o.Alpha, o.TieDensity = DistributionPowerLaw, 2.5, 0 },
+			check: func(t *testing.T, ratings []int) {
+				if m := median(ratings); m > models.MinRating+300 {
+					t.Errorf("median %d, want most users near %d", m, models.MinRating)
+				}
+			},
+		},
+		{
+			name: "ties",
+			opts: func(o *Options) { o.Distribution, o.Tiers, o.TieDensity = DistributionTiered, tiers, 1 },
+			check: func(t *testing.T, ratings []int) {
+				for _, r := range ratings {
+					if r%tieStep != 0 {
+						t.Fatalf("rating %d is not snapped to a multiple of %d", r, tieStep)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions()
+			tt.opts(&opts)
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("invalid options: %v", err)
+			}
+			data := Generate(opts)
+			if len(data.Users) != opts.Count {
+				t.Fatalf("generated %d users, want %d", len(data.Users), opts.Count)
+			}
+
+			ratings := make([]int, len(data.Users))
+			for i, user := range data.Users {
+				ratings[i] = user.Rating
+				if user.Rating < models.MinRating || user.Rating > models.MaxRating {
+					t.Fatalf("user %d has rating %d, outside %d-%d", user.ID, user.Rating, models.MinRating, models.MaxRating)
+				}
+				if user.PeakRating < user.Rating {
+					t.Fatalf("user %d has peak %d below rating %d", user.ID, user.PeakRating, user.Rating)
+				}
+			}
+			for _, m := range data.Matches {
+				for _, r := range []int{m.PlayerOneOld, m.PlayerOneNew, m.PlayerTwoOld, m.PlayerTwoNew} {
+					if r < models.MinRating || r > models.MaxRating {
+						t.Fatalf("match %d has rating %d, outside %d-%d", m.ID, r, models.MinRating, models.MaxRating)
+					}
+				}
+			}
+			tt.check(t, ratings)
+		})
+	}
+}
+
+func TestGenerateMatchHistory(t *testing.T) {
+	opts := testOptions()
+	data := Generate(opts)
+	if want := opts.Count * opts.MatchesPerUser / 2; len(data.Matches) != want {
+		t.Fatalf("generated %d matches, want %d", len(data.Matches), want)
+	}
+
+	start := opts.EndTime.AddDate(0, 0, -opts.HistoryDays)
+	current := make(map[int]int)
+	for i, m := range data.Matches {
+		if m.ID != i+1 {
+			t.Fatalf("match %d has ID %d", i+1, m.ID)
+		}
+		if m.PlayedAt.Before(start) || !m.PlayedAt.Before(opts.EndTime) {
+			t.Fatalf("match %d played at %s, outside %s-%s", m.ID, m.PlayedAt, start, opts.EndTime)
+		}
+		if i > 0 && m.PlayedAt.Before(data.Matches[i-1].PlayedAt) {
+			t.Fatalf("match %d is out of order", m.ID)
+		}
+		if m.PlayerOne == m.PlayerTwo {
+			t.Fatalf("match %d pairs user %d with themselves", m.ID, m.PlayerOne)
+		}
+		current[m.PlayerOne], current[m.PlayerTwo] = m.PlayerOneNew, m.PlayerTwoNew
+	}
+	// History ends at the generated ratings
+	for _, user := range data.Users {
+		if r, ok := current[user.ID]; ok && r != user.Rating {
+			t.Fatalf("user %d ends history at %d but has rating %d", user.ID, r, user.Rating)
+		}
+	}
+}
+
+func TestGenerateUsernames(t *testing.T) {
+	pattern := regexp.MustCompile(`^(user|player|gamer)_?[0-9]+(_[0-9]+)*$`)
+	data := Generate(testOptions())
+
+	seen := make(map[string]bool, len(data.Users))
+	for _, user := range data.Users {
+		if !pattern.MatchString(user.Username) {
+			t.Errorf("username %q does not follow the generated patterns", user.Username)
+		}
+		if n := len(user.Username); n < 3 || n > 32 {
+			t.Errorf("username %q has %d characters, want 3-32", user.Username, n)
+		}
+		if seen[user.Username] {
+			t.Errorf("username %q generated twice", user.Username)
+		}
+		seen[user.Username] = true
+	}
+}
+
+func median(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
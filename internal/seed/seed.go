@@ -0,0 +1,218 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"matiks/leaderboard/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// Options controls what Generate and Run produce. The same options always
+// produce the same dataset.
+type Options struct {
+	Count        int
+	Distribution string
+	// Tiers are used by DistributionTiered
+	Tiers []Tier
+	// Mean and StdDev shape DistributionNormal
+	Mean   float64
+	StdDev float64
+	// Alpha is the DistributionPowerLaw exponent; lower means a longer tail
+	Alpha float64
+	// TieDensity is the share of users whose rating is snapped to a multiple
+	// of 500, tying them with each other
+	TieDensity float64
+	// MatchesPerUser is the average number of matches each user played; 0
+	// skips match and rating history generation
+	MatchesPerUser int
+	// HistoryDays is how far back matches go from EndTime
+	HistoryDays int
+	// EndTime is when the generated history ends. Fix it for datasets that
+	// must be identical across days.
+	EndTime time.Time
+	// Seed for the random generator
+	Seed int64
+	// Truncate replaces existing users, matches and history; without it Run
+	// refuses to seed a non-empty database
+	Truncate bool
+}
+
+// DefaultOptions returns a 10k user tiered board with a month of matches
+// ending at the start of the current UTC day
+func DefaultOptions() Options {
+	return Options{
+		Count:          10000,
+		Distribution:   DistributionTiered,
+		Tiers:          DefaultTiers,
+		Mean:           2500,
+		StdDev:         700,
+		Alpha:          2.5,
+		TieDensity:     0.2,
+		MatchesPerUser: 5,
+		HistoryDays:    30,
+		EndTime:        time.Now().UTC().Truncate(24 * time.Hour),
+		Seed:           1,
+	}
+}
+
+// Validate checks the options before anything is generated
+func (o Options) Validate() error {
+	if o.Count < 1 {
+		return fmt.Errorf("count must be at least 1")
+	}
+	if !slices.Contains(Distributions, o.Distribution) {
+		return fmt.Errorf("unknown distribution %q (want one of %v)", o.Distribution, Distributions)
+	}
+	switch o.Distribution {
+	case DistributionTiered:
+		if err := validateTiers(o.Tiers); err != nil {
+			return err
+		}
+	case DistributionNormal:
+		if o.StdDev <= 0 {
+			return fmt.Errorf("standard deviation must be positive")
+		}
+	case DistributionPowerLaw:
+		if o.Alpha <= 0 {
+			return fmt.Errorf("alpha must be positive")
+		}
+	}
+	if o.TieDensity < 0 || o.TieDensity > 1 {
+		return fmt.Errorf("tie density must be between 0 and 1")
+	}
+	if o.MatchesPerUser < 0 || o.HistoryDays < 0 {
+		return fmt.Errorf("matches per user and history days must not be negative")
+	}
+	if o.MatchesPerUser > 0 && (o.HistoryDays == 0 || o.Count < 2) {
+		return fmt.Errorf("matches need at least 2 users and 1 day of history")
+	}
+	if o.EndTime.IsZero() {
+		return fmt.Errorf("end time is required")
+	}
+	return nil
+}
+
+// Result counts the rows Run wrote
+type Result struct {
+	Users   int
+	Matches int
+	History int
+}
+
+// Run generates a dataset and loads it with COPY in a single transaction, so
+// a failed seed leaves the database untouched. Redis is not updated; run a
+// sync afterwards.
+func Run(ctx context.Context, db *gorm.DB, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Generating %d users (%s distribution, seed %d)...", opts.Count, opts.Distribution, opts.Seed)
+	start := time.Now()
+	data := Generate(opts)
+	log.Printf("Generated %d users and %d matches in %v", len(data.Users), len(data.Matches), time.Since(start))
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	start = time.Now()
+	result := &Result{}
+	err = conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		return pgx.BeginFunc(ctx, pgConn, func(tx pgx.Tx) error {
+			return load(ctx, tx, data, opts.Truncate, result)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Seeded %d users, %d matches and %d history rows in %v",
+		result.Users, result.Matches, result.History, time.Since(start))
+	return result, nil
+}
+
+// load copies data into the tables and moves their id sequences past the
+// explicit IDs
+func load(ctx context.Context, tx pgx.Tx, data *Dataset, truncate bool, result *Result) error {
+	var existing int64
+	if err := tx.QueryRow(ctx, "SELECT count(*) FROM users").Scan(&existing); err != nil {
+		return err
+	}
+	if existing > 0 {
+		if !truncate {
+			return fmt.Errorf("database already contains %d users; pass --truncate to replace them", existing)
+		}
+		log.Printf("Clearing %d existing users...", existing)
+		// CASCADE also empties every table referencing users
+		if _, err := tx.Exec(ctx, "TRUNCATE TABLE users, matches, rating_history RESTART IDENTITY CASCADE"); err != nil {
+			return fmt.Errorf("failed to truncate: %w", err)
+		}
+	}
+
+	users, matches := data.Users, data.Matches
+	n, err := tx.CopyFrom(ctx, pgx.Identifier{"users"},
+		[]string{"id", "username", "rating", "peak_rating", "peak_rating_at", "last_active_at", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(users), func(i int) ([]any, error) {
+			u := users[i]
+			return []any{u.ID, u.Username, u.Rating, u.PeakRating, u.PeakRatingAt, u.UpdatedAt, u.CreatedAt, u.UpdatedAt}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("failed to copy users: %w", err)
+	}
+	result.Users = int(n)
+
+	n, err = tx.CopyFrom(ctx, pgx.Identifier{"matches"},
+		[]string{"id", "player_one_id", "player_two_id", "winner_id", "played_at"},
+		pgx.CopyFromSlice(len(matches), func(i int) ([]any, error) {
+			m := matches[i]
+			var winner *int
+			if m.Winner != 0 {
+				winner = &m.Winner
+			}
+			return []any{m.ID, m.PlayerOne, m.PlayerTwo, winner, m.PlayedAt}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("failed to copy matches: %w", err)
+	}
+	result.Matches = int(n)
+
+	// Two history rows per match, one for each player
+	n, err = tx.CopyFrom(ctx, pgx.Identifier{"rating_history"},
+		[]string{"user_id", "old_rating", "new_rating", "source", "match_id", "created_at"},
+		pgx.CopyFromSlice(2*len(matches), func(i int) ([]any, error) {
+			m := matches[i/2]
+			if i%2 == 0 {
+				return []any{m.PlayerOne, m.PlayerOneOld, m.PlayerOneNew, models.RatingSourceMatch, m.ID, m.PlayedAt}, nil
+			}
+			return []any{m.PlayerTwo, m.PlayerTwoOld, m.PlayerTwoNew, models.RatingSourceMatch, m.ID, m.PlayedAt}, nil
+		}))
+	if err != nil {
+		return fmt.Errorf("failed to copy rating history: %w", err)
+	}
+	result.History = int(n)
+
+	// COPY bypasses the id defaults, so later inserts would collide with 1..N
+	for _, table := range []string{"users", "matches"} {
+		_, err := tx.Exec(ctx, fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), COALESCE((SELECT MAX(id) FROM %[1]s), 0) + 1, false)", table))
+		if err != nil {
+			return fmt.Errorf("failed to reset %s id sequence: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"matiks/leaderboard/internal/achievements"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AchievementService awards achievements as rating changes are applied, and
+// in a periodic sweep for the ones that depend on time or on other players:
+// the sweep backfills rating achievements from peak ratings and tracks who
+// stays in a top percentage without playing.
+type AchievementService struct {
+	userRepo        *repository.UserRepository
+	redisRepo       *repository.RedisRepository
+	achievementRepo *repository.AchievementRepository
+	definitions     []achievements.Definition
+	byID            map[string]achievements.Definition
+}
+
+func NewAchievementService(
+	userRepo *repository.UserRepository,
+	redisRepo *repository.RedisRepository,
+	achievementRepo *repository.AchievementRepository,
+	definitions []achievements.Definition,
+) *AchievementService {
+	byID := make(map[string]achievements.Definition, len(definitions))
+	for _, d := range definitions {
+		byID[d.ID] = d
+	}
+	return &AchievementService{
+		userRepo:        userRepo,
+		redisRepo:       redisRepo,
+		achievementRepo: achievementRepo,
+		definitions:     definitions,
+		byID:            byID,
+	}
+}
+
+// evaluation caches what judging one change needs across definitions
+type evaluation struct {
+	change   RatingChange
+	newRank  int
+	total    int64
+	ranked   bool
+	ratingAt map[time.Duration]*int
+}
+
+// OnRatingChange is an UpdateObserver that awards the achievements a change
+// earns and tracks progress towards top-percentage ones. Failures are
+// logged; the rating change itself has already been applied.
+func (s *AchievementService) OnRatingChange(ctx context.Context, change RatingChange) {
+	if !change.Ranked || len(s.definitions) == 0 {
+		return
+	}
+	held, err := s.achievementRepo.HeldBy(ctx, change.UserID)
+	if err != nil {
+		log.Printf("Failed to load achievements of %s: %v", change.Username, err)
+		return
+	}
+	holds := make(map[string]bool, len(held))
+	for _, achievement := range held {
+		holds[achievement.AchievementID] = true
+	}
+
+	e := &evaluation{change: change, ratingAt: make(map[time.Duration]*int)}
+	for _, d := range s.definitions {
+		if holds[d.ID] {
+			continue
+		}
+		earned, err := s.evaluate(ctx, d, e)
+		if err != nil {
+			log.Printf("Failed to evaluate achievement %s for %s: %v", d.ID, change.Username, err)
+			continue
+		}
+		if earned {
+			s.award(ctx, change.UserID, change.Username, d)
+		}
+	}
+}
+
+// evaluate reports whether the change earns d
+func (s *AchievementService) evaluate(ctx context.Context, d achievements.Definition, e *evaluation) (bool, error) {
+	switch d.Kind {
+	case achievements.KindRating:
+		return e.change.NewRating >= d.Rating, nil
+
+	case achievements.KindRankClimb:
+		if e.change.NewRating <= e.change.OldRating {
+			return false, nil
+		}
+		then, err := s.ratingAt(ctx, e, time.Duration(d.Window))
+		if err != nil || then == nil || *then >= e.change.NewRating {
+			return false, err
+		}
+		// Both ranks are taken on today's board, so only the user's own
+		// climb counts, not others dropping
+		oldRank, err := rankForRating(ctx, s.userRepo, s.redisRepo, *then, "achievement_ranks")
+		if err != nil {
+			return false, err
+		}
+		newRank, err := s.rank(ctx, e)
+		if err != nil {
+			return false, err
+		}
+		// The user now counts among those rated above their old rating
+		return (oldRank-1)-newRank >= d.Ranks, nil
+
+	case achievements.KindTopPercent:
+		newRank, err := s.rank(ctx, e)
+		if err != nil {
+			return false, err
+		}
+		if e.total == 0 {
+			if e.total, err = totalRankedUsers(ctx, s.userRepo, s.redisRepo); err != nil {
+				return false, err
+			}
+		}
+		if int64(newRank) > d.TopRank(e.total) {
+			return false, s.achievementRepo.StopProgress(ctx, e.change.UserID, d.ID)
+		}
+		since, err := s.achievementRepo.StartProgress(ctx, e.change.UserID, d.ID)
+		if err != nil {
+			return false, err
+		}
+		return time.Since(since) >= time.Duration(d.Hold), nil
+	}
+	return false, nil
+}
+
+// rank returns the user's rank after the change
+func (s *AchievementService) rank(ctx context.Context, e *evaluation) (int, error) {
+	if !e.ranked {
+		rank, err := rankForRating(ctx, s.userRepo, s.redisRepo, e.change.NewRating, "achievement_ranks")
+		if err != nil {
+			return 0, err
+		}
+		e.newRank, e.ranked = rank, true
+	}
+	return e.newRank, nil
+}
+
+// ratingAt returns the user's rating window before the change, or nil if it
+// is not known
+func (s *AchievementService) ratingAt(ctx context.Context, e *evaluation, window time.Duration) (*int, error) {
+	if rating, ok := e.ratingAt[window]; ok {
+		return rating, nil
+	}
+	rating, found, err := s.userRepo.GetRatingAt(ctx, e.change.UserID, e.change.AppliedAt.Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	var result *int
+	if found {
+		result = &rating
+	}
+	e.ratingAt[window] = result
+	return result, nil
+}
+
+func (s *AchievementService) award(ctx context.Context, userID int, username string, d achievements.Definition) {
+	awarded, err := s.achievementRepo.Award(ctx, userID, d.ID)
+	if err != nil {
+		log.Printf("Failed to award achievement %s to %s: %v", d.ID, username, err)
+		return
+	}
+	if !awarded {
+		return
+	}
+	metrics.AchievementsAwarded.WithLabelValues(d.ID).Inc()
+	log.Printf("Awarded achievement %s to %s", d.ID, username)
+	if d.Kind == achievements.KindTopPercent {
+		if err := s.achievementRepo.StopProgress(ctx, userID, d.ID); err != nil {
+			log.Printf("Failed to clear progress of %s towards %s: %v", username, d.ID, err)
+		}
+	}
+}
+
+// Sweep awards rating achievements to every user whose peak rating earns
+// them, and updates who is in each top percentage, awarding those who have
+// stayed there long enough. Returns how many achievements were awarded.
+func (s *AchievementService) Sweep(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "AchievementService.Sweep")
+	defer func() { tracing.End(span, err) }()
+
+	var total int64
+	for _, d := range s.definitions {
+		var awarded int64
+		switch d.Kind {
+		case achievements.KindRating:
+			awarded, err = s.achievementRepo.AwardPeakRating(ctx, d.ID, d.Rating)
+		case achievements.KindTopPercent:
+			awarded, err = s.sweepTop(ctx, d)
+		default:
+			continue
+		}
+		if err != nil {
+			return total, err
+		}
+		if awarded > 0 {
+			metrics.AchievementsAwarded.WithLabelValues(d.ID).Add(float64(awarded))
+		}
+		total += awarded
+	}
+	span.SetAttributes(attribute.Int64("achievements.awarded", total))
+	return total, nil
+}
+
+func (s *AchievementService) sweepTop(ctx context.Context, d achievements.Definition) (int64, error) {
+	ranked, err := s.userRepo.GetTotalUsers(ctx)
+	if err != nil || ranked == 0 {
+		return 0, err
+	}
+	// Everyone tied with the user at the cutoff position is in the top too
+	cutoff, found, err := s.userRepo.RatingAtPosition(ctx, d.TopRank(ranked))
+	if err != nil || !found {
+		return 0, err
+	}
+	return s.achievementRepo.SweepTop(ctx, d.ID, cutoff, time.Now().Add(-time.Duration(d.Hold)))
+}
+
+// ForUser returns the defined achievements a user holds, oldest first
+func (s *AchievementService) ForUser(ctx context.Context, userID int) ([]models.Achievement, error) {
+	held, err := s.achievementRepo.HeldBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.Achievement, 0, len(held))
+	for _, achievement := range held {
+		// Awards outlive definitions removed from the configuration
+		d, ok := s.byID[achievement.AchievementID]
+		if !ok {
+			continue
+		}
+		result = append(result, models.Achievement{ID: d.ID, Name: d.Name, Description: d.Description, AwardedAt: achievement.AwardedAt})
+	}
+	return result, nil
+}
+
+// Stats returns every defined achievement with how many players hold it
+func (s *AchievementService) Stats(ctx context.Context) (_ *models.AchievementStatsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AchievementService.Stats")
+	defer func() { tracing.End(span, err) }()
+
+	holders, err := s.achievementRepo.Holders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ranked, err := totalRankedUsers(ctx, s.userRepo, s.redisRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AchievementStatsResponse{Achievements: make([]models.AchievementStat, 0, len(s.definitions)), RankedUsers: ranked}
+	for _, d := range s.definitions {
+		stat := models.AchievementStat{ID: d.ID, Name: d.Name, Description: d.Description, Criteria: d.Criteria(), Holders: holders[d.ID]}
+		if ranked > 0 {
+			stat.Share = math.Round(float64(stat.Holders)/float64(ranked)*10000) / 100
+		}
+		response.Achievements = append(response.Achievements, stat)
+	}
+	return response, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"matiks/leaderboard/internal/anticheat"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+)
+
+// AntiCheatService screens queued rating updates in the update workers.
+// Flagged updates are applied and recorded, held updates wait in the review
+// queue for a moderator, and rejected updates are dropped and recorded.
+type AntiCheatService struct {
+	userRepo      *repository.UserRepository
+	antiCheatRepo *repository.AntiCheatRepository
+	engine        *anticheat.Engine
+	samples       int
+	enabled       bool
+}
+
+func NewAntiCheatService(userRepo *repository.UserRepository, antiCheatRepo *repository.AntiCheatRepository, cfg config.AntiCheatConfig) *AntiCheatService {
+	var bindings []anticheat.Binding
+	if cfg.MaxDelta.Threshold > 0 {
+		bindings = append(bindings, anticheat.Binding{Rule: anticheat.MaxDelta{Limit: int(cfg.MaxDelta.Threshold)}, Action: cfg.MaxDelta.Action})
+	}
+	if cfg.MaxHourlyGain.Threshold > 0 {
+		bindings = append(bindings, anticheat.Binding{Rule: anticheat.MaxHourlyGain{Limit: int(cfg.MaxHourlyGain.Threshold)}, Action: cfg.MaxHourlyGain.Action})
+	}
+	samples := 0
+	if cfg.Outlier.Threshold > 0 {
+		samples = cfg.OutlierSamples
+		bindings = append(bindings, anticheat.Binding{Rule: anticheat.Outlier{ZScore: cfg.Outlier.Threshold, MinSamples: samples}, Action: cfg.Outlier.Action})
+	}
+	return &AntiCheatService{
+		userRepo:      userRepo,
+		antiCheatRepo: antiCheatRepo,
+		engine:        anticheat.New(bindings...),
+		samples:       samples,
+		enabled:       len(bindings) > 0,
+	}
+}
+
+// screening holds what the rules need to judge one update. It is loaded
+// before the user is locked, so the lock is not held across queries.
+type screening struct {
+	userID  int
+	history anticheat.History
+}
+
+// prepare loads the user's recent history for an update, or returns nil if
+// the update is not screened
+func (s *AntiCheatService) prepare(ctx context.Context, update UpdateRequest) (*screening, error) {
+	if !s.enabled || !anticheat.Checked(update.Source) {
+		return nil, nil
+	}
+	user, err := s.userRepo.GetUserByUsername(ctx, update.Username)
+	if err != nil {
+		return nil, userLookupError(err, update.Username)
+	}
+	net, deltas, err := s.antiCheatRepo.RecentChanges(ctx, user.ID, anticheat.CheckedSources, time.Now().Add(-time.Hour), s.samples)
+	if err != nil {
+		return nil, err
+	}
+	return &screening{userID: user.ID, history: anticheat.History{HourlyGain: net, Deltas: deltas}}, nil
+}
+
+// screen judges the update against the locked user
+func (s *AntiCheatService) screen(sc *screening, user models.User, newRating int) anticheat.Verdict {
+	update := anticheat.Update{OldRating: user.Rating, NewRating: newRating}
+	if user.ID == sc.userID {
+		update.History = sc.history
+	}
+	return s.engine.Evaluate(update)
+}
+
+// record stores the anomaly for a verdict on which a rule fired
+func (s *AntiCheatService) record(ctx context.Context, user models.User, update UpdateRequest, verdict anticheat.Verdict) {
+	for _, hit := range verdict.Hits {
+		metrics.AntiCheatHits.WithLabelValues(hit.Rule, hit.Action).Inc()
+	}
+
+	status := models.AnomalyFlagged
+	switch verdict.Action {
+	case models.AntiCheatHold:
+		status = models.AnomalyPending
+	case models.AntiCheatReject:
+		status = models.AnomalyRejected
+	}
+	anomaly := &models.RatingAnomaly{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Source:    update.Source,
+		OldRating: user.Rating,
+		NewRating: update.NewRating,
+		Action:    verdict.Action,
+		Rules:     verdict.Hits,
+		Status:    status,
+	}
+	if err := s.antiCheatRepo.Create(ctx, anomaly); err != nil {
+		// A held update that is not recorded is lost; make that loud
+		log.Printf("Failed to record %s anomaly for %s (%d -> %d): %v", verdict.Action, user.Username, user.Rating, update.NewRating, err)
+		return
+	}
+	log.Printf("Anti-cheat %s on %s (%d -> %d), anomaly %d", verdict.Action, user.Username, user.Rating, update.NewRating, anomaly.ID)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AuditService writes and queries the audit log of privileged actions
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+}
+
+func NewAuditService(auditRepo *repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record appends an event to the audit log
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.Record", trace.WithAttributes(
+		attribute.String("audit.action", event.Action),
+		attribute.String("audit.outcome", event.Outcome),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.auditRepo.Record(ctx, event)
+}
+
+// List returns a page of audit events matching filter, newest first
+func (s *AuditService) List(ctx context.Context, filter repository.AuditFilter, page, limit int) (_ *models.AuditLogResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.List", trace.WithAttributes(
+		attribute.Int("audit.page", page),
+		attribute.Int("audit.limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return nil, apperrors.Validation("since must be before until")
+	}
+	if filter.Outcome != "" && filter.Outcome != models.AuditSuccess && filter.Outcome != models.AuditFailure {
+		return nil, apperrors.Validation("outcome must be %s or %s", models.AuditSuccess, models.AuditFailure).
+			WithDetail("outcome", filter.Outcome)
+	}
+
+	events, err := s.auditRepo.List(ctx, filter, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.auditRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuditLogResponse{Events: events, Page: page, Limit: limit, Total: total}, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/auth"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedResolution limits how often last_used_at is written for a busy key
+const lastUsedResolution = time.Minute
+
+type AuthService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	jwt        *auth.JWTVerifier
+}
+
+// NewAuthService creates an AuthService. jwt may be nil to accept API keys only.
+func NewAuthService(apiKeyRepo *repository.APIKeyRepository, jwt *auth.JWTVerifier) *AuthService {
+	return &AuthService{apiKeyRepo: apiKeyRepo, jwt: jwt}
+}
+
+// Authenticate resolves a bearer credential (API key or JWT) to a principal
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	if auth.IsAPIKey(credential) {
+		return s.authenticateAPIKey(ctx, credential)
+	}
+	if s.jwt == nil {
+		return nil, apperrors.Unauthorized("invalid API key")
+	}
+	principal, err := s.jwt.Verify(credential)
+	if err != nil {
+		return nil, apperrors.Unauthorized("invalid bearer token").Wrap(err)
+	}
+	return principal, nil
+}
+
+func (s *AuthService) authenticateAPIKey(ctx context.Context, credential string) (*auth.Principal, error) {
+	key, err := s.apiKeyRepo.FindActiveByHash(ctx, auth.HashAPIKey(credential))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.Unauthorized("invalid API key")
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			log.Printf("Failed to record API key %d usage: %v", key.ID, err)
+		}
+	}
+
+	scopes, err := auth.ParseScopes(key.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("api key %d has invalid scopes: %w", key.ID, err)
+	}
+
+	return &auth.Principal{
+		Subject: fmt.Sprintf("apikey:%d", key.ID),
+		Name:    key.Name,
+		Method:  auth.MethodAPIKey,
+		Scopes:  scopes,
+	}, nil
+}
+
+// CreateAPIKey generates and stores a new key; the plaintext is only returned here
+func (s *AuthService) CreateAPIKey(ctx context.Context, name, scopes string) (*models.CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperrors.Validation("name is required")
+	}
+	parsed, err := auth.ParseScopes(scopes)
+	if err != nil {
+		return nil, apperrors.Validation("%v", err).WithDetail("scopes", scopes)
+	}
+	if len(parsed) == 0 {
+		return nil, apperrors.Validation("at least one scope is required")
+	}
+
+	plaintext, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.APIKey{
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: auth.HashAPIKey(plaintext),
+		Scopes:  auth.JoinScopes(parsed),
+	}
+	if err := s.apiKeyRepo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+	audit.SetTarget(ctx, "api_key", strconv.Itoa(key.ID))
+	audit.SetChange(ctx, nil, key)
+
+	return &models.CreatedAPIKey{APIKey: key, Key: plaintext}, nil
+}
+
+// ListAPIKeys returns all keys without their secrets
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.apiKeyRepo.List(ctx)
+}
+
+// RevokeAPIKey disables a key immediately
+func (s *AuthService) RevokeAPIKey(ctx context.Context, id int) error {
+	if err := s.apiKeyRepo.Revoke(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NotFound("api key %d not found", id).WithDetail("id", id)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"matiks/leaderboard/internal/repository"
+)
+
+const (
+	// maxRedisTimeout caps a single Redis attempt regardless of the request deadline
+	maxRedisTimeout = 500 * time.Millisecond
+	// redisBudgetShare is the share of the remaining request deadline Redis may use,
+	// leaving the rest for the Postgres fallback
+	redisBudgetShare = 0.5
+)
+
+// redisBudget derives a context for a Redis attempt that leaves enough of the
+// caller's deadline for a Postgres fallback if Redis turns out to be slow.
+func redisBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := maxRedisTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if share := time.Duration(float64(time.Until(deadline)) * redisBudgetShare); share < timeout {
+			timeout = share
+		}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// errRedisDisabled is returned by Redis reads when no Redis is configured
+var errRedisDisabled = errors.New("redis is not configured")
+
+// redisFallbackReason labels why a Redis read was abandoned
+func redisFallbackReason(err error) string {
+	switch {
+	case errors.Is(err, errRedisDisabled):
+		return "redis_disabled"
+	case errors.Is(err, repository.ErrRedisUnavailable):
+		return "circuit_open"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "redis_timeout"
+	default:
+		return "redis_error"
+	}
+}
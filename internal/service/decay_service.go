@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxReportedDecayActions caps the actions kept in a DecayReport
+const maxReportedDecayActions = 500
+
+// DecayService applies rating decay to inactive users. A user inactive for
+// longer than InactiveDays owes one decay period, plus one more for every
+// Period after that. In points mode each period costs Points, down to Floor,
+// and is written as its own rating change through UpdateService.Apply, so
+// rating_history shows every period applied. Counting those changes makes a
+// run idempotent: users are only charged the periods they still owe. In hide
+// mode inactive users are hidden until their next rating change.
+type DecayService struct {
+	userRepo      *repository.UserRepository
+	redisRepo     *repository.RedisRepository
+	decayRepo     *repository.DecayRepository
+	updateService *UpdateService
+	broadcaster   *cache.Broadcaster
+	config        config.DecayConfig
+
+	// running keeps runs on this instance from overlapping
+	running sync.Mutex
+}
+
+func NewDecayService(
+	userRepo *repository.UserRepository,
+	redisRepo *repository.RedisRepository,
+	decayRepo *repository.DecayRepository,
+	updateService *UpdateService,
+	broadcaster *cache.Broadcaster,
+	cfg config.DecayConfig,
+) *DecayService {
+	return &DecayService{
+		userRepo:      userRepo,
+		redisRepo:     redisRepo,
+		decayRepo:     decayRepo,
+		updateService: updateService,
+		broadcaster:   broadcaster,
+		config:        cfg,
+	}
+}
+
+// Run examines every inactive ranked user in batches and decays or hides
+// those who owe it. A dry run only reports what would happen. Every run,
+// dry or not, is recorded in decay_runs.
+func (s *DecayService) Run(ctx context.Context, dryRun bool) (_ *models.DecayReport, err error) {
+	ctx, span := tracing.Start(ctx, "DecayService.Run", trace.WithAttributes(
+		attribute.Bool("decay.dry_run", dryRun),
+		attribute.String("decay.mode", s.config.Mode),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if s.config.Mode == models.DecayOff {
+		return nil, apperrors.Validation("rating decay is disabled; set DECAY_MODE to %s or %s", models.DecayPoints, models.DecayHide)
+	}
+	if !s.running.TryLock() {
+		return nil, apperrors.Conflict("a decay run is already in progress")
+	}
+	defer s.running.Unlock()
+
+	report := &models.DecayReport{
+		DecayRun: models.DecayRun{StartedAt: time.Now(), DryRun: dryRun, Mode: s.config.Mode},
+		Actions:  []models.DecayAction{},
+	}
+	if err := s.decayRepo.CreateRun(ctx, &report.DecayRun); err != nil {
+		return nil, err
+	}
+
+	runErr := s.sweep(ctx, report)
+
+	finished := time.Now()
+	report.FinishedAt = &finished
+	if runErr != nil {
+		msg := runErr.Error()
+		report.Error = &msg
+	}
+	// Record the outcome even if the request that started the run went away
+	if err := s.decayRepo.SaveRun(context.WithoutCancel(ctx), &report.DecayRun); err != nil {
+		log.Printf("Failed to record decay run %d: %v", report.ID, err)
+	}
+
+	log.Printf("Decay run %d (%s, dry run %t): examined %d, decayed %d, hidden %d, %d points removed in %v",
+		report.ID, report.Mode, dryRun, report.Examined, report.Decayed, report.Hidden, report.PointsRemoved,
+		finished.Sub(report.StartedAt))
+	audit.SetChange(ctx, nil, report.DecayRun)
+	if runErr != nil {
+		return nil, runErr
+	}
+	return report, nil
+}
+
+// Runs returns the most recent decay runs
+func (s *DecayService) Runs(ctx context.Context, limit int) ([]models.DecayRun, error) {
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+	return s.decayRepo.ListRuns(ctx, limit)
+}
+
+// sweep walks the inactive users in ID order. A user who cannot be written is
+// logged and skipped so one bad row doesn't stall every later run; the run
+// then reports the failures.
+func (s *DecayService) sweep(ctx context.Context, report *models.DecayReport) error {
+	now := report.StartedAt
+	cutoff := now.Add(-time.Duration(s.config.InactiveDays) * 24 * time.Hour)
+	minRating := 0
+	if s.config.Mode == models.DecayPoints {
+		// Users at the floor have nothing left to lose
+		minRating = s.config.Floor
+	}
+
+	var failed int
+	var firstErr error
+	afterID := 0
+	for {
+		candidates, err := s.userRepo.ListInactiveUsers(ctx, cutoff, minRating, afterID, s.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, candidate := range candidates {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			report.Examined++
+
+			var action *models.DecayAction
+			if s.config.Mode == models.DecayPoints {
+				action, err = s.decay(ctx, candidate, now, report.DryRun)
+			} else {
+				action, err = s.hide(ctx, candidate, report.DryRun)
+			}
+			if err != nil {
+				log.Printf("Decay of %s failed: %v", candidate.Username, err)
+				if failed++; firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if action == nil {
+				continue
+			}
+
+			if action.Action == models.DecayHide {
+				report.Hidden++
+			} else {
+				report.Decayed++
+				report.PointsRemoved += action.OldRating - action.NewRating
+			}
+			if len(report.Actions) < maxReportedDecayActions {
+				report.Actions = append(report.Actions, *action)
+			}
+		}
+
+		if len(candidates) < s.config.BatchSize {
+			break
+		}
+		afterID = candidates[len(candidates)-1].ID
+	}
+
+	if report.Hidden > 0 && !report.DryRun {
+		// Users left the board, which shifts every page's total
+		s.broadcaster.InvalidateAll(ctx)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d users could not be updated, first error: %w", failed, firstErr)
+	}
+	return nil
+}
+
+// decay charges a user the periods they owe, one rating change per period.
+// Each change re-checks the locked user, so a user who played or changed in
+// the meantime is left alone.
+func (s *DecayService) decay(ctx context.Context, candidate repository.DecayCandidate, now time.Time, dryRun bool) (*models.DecayAction, error) {
+	owed := s.periodsDue(candidate.LastActiveAt, now) - candidate.Decays
+	if owed <= 0 {
+		return nil, nil
+	}
+
+	action := &models.DecayAction{
+		Username:     candidate.Username,
+		LastActiveAt: candidate.LastActiveAt,
+		Action:       models.DecayPoints,
+		OldRating:    candidate.Rating,
+		NewRating:    candidate.Rating,
+	}
+	for ; action.Periods < owed && action.NewRating > s.config.Floor; action.Periods++ {
+		expected := action.NewRating
+		next := max(s.config.Floor, expected-s.config.Points)
+		if dryRun {
+			action.NewRating = next
+			continue
+		}
+
+		change, err := s.updateService.Apply(ctx, candidate.Username, repository.RatingWrite{Source: models.RatingSourceDecay}, func(user models.User) (int, bool) {
+			return next, user.Ranked() && user.Rating == expected && user.LastActiveAt.Equal(candidate.LastActiveAt)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if change == nil {
+			break
+		}
+		action.NewRating = change.NewRating
+	}
+
+	if action.Periods == 0 {
+		return nil, nil
+	}
+	return action, nil
+}
+
+// hide takes an inactive user off the leaderboard until they play again
+func (s *DecayService) hide(ctx context.Context, candidate repository.DecayCandidate, dryRun bool) (*models.DecayAction, error) {
+	action := &models.DecayAction{
+		Username:     candidate.Username,
+		LastActiveAt: candidate.LastActiveAt,
+		Action:       models.DecayHide,
+		OldRating:    candidate.Rating,
+		NewRating:    candidate.Rating,
+	}
+	if dryRun {
+		return action, nil
+	}
+
+	hidden := false
+	_, err := s.userRepo.ModifyUser(ctx, candidate.Username,
+		func(user *models.User) (bool, error) {
+			if !user.Ranked() || !user.LastActiveAt.Equal(candidate.LastActiveAt) {
+				return false, nil
+			}
+			hidden = setHidden(user, true, models.HiddenInactive)
+			return hidden, nil
+		},
+		func(before, after models.User) {
+			mirrorToRedis(ctx, s.redisRepo, before, after)
+		})
+	if err != nil || !hidden {
+		return nil, err
+	}
+	return action, nil
+}
+
+// periodsDue counts the decay periods a user last active at lastActive owes
+// in total at now
+func (s *DecayService) periodsDue(lastActive, now time.Time) int {
+	overdue := now.Sub(lastActive) - time.Duration(s.config.InactiveDays)*24*time.Hour
+	if overdue <= 0 {
+		return 0
+	}
+	return 1 + int(overdue/s.config.Period)
+}
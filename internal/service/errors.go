@@ -0,0 +1,18 @@
+package service
+
+import (
+	"errors"
+
+	"matiks/leaderboard/internal/apperrors"
+
+	"gorm.io/gorm"
+)
+
+// userLookupError converts a failed user lookup into a domain error.
+// GORM reports a missing row as "record not found", which must surface as a 404.
+func userLookupError(err error, username string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound("user %q not found", username).WithDetail("username", username)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/events"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EventService publishes applied rating changes to the event stream in Redis
+// and replays them for clients catching up. Events are published after the
+// change is written, so an event lost while Redis is down is not retried;
+// Postgres stays the source of truth.
+type EventService struct {
+	userRepo  *repository.UserRepository
+	redisRepo *repository.RedisRepository
+	config    config.EventsConfig
+}
+
+func NewEventService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, cfg config.EventsConfig) *EventService {
+	return &EventService{userRepo: userRepo, redisRepo: redisRepo, config: cfg}
+}
+
+// OnRatingChange is an UpdateObserver that appends a RatingChanged event
+func (s *EventService) OnRatingChange(ctx context.Context, change RatingChange) {
+	if !s.redisRepo.Available() {
+		metrics.EventsPublished.WithLabelValues(models.EventRatingChanged, "dropped").Inc()
+		return
+	}
+
+	oldRank, newRank, err := changeRanks(ctx, s.userRepo, s.redisRepo, change, "event_ranks")
+	if err != nil {
+		metrics.EventsPublished.WithLabelValues(models.EventRatingChanged, "failed").Inc()
+		log.Printf("Failed to rank rating change for %s, event dropped: %v", change.Username, err)
+		return
+	}
+	values, err := events.Values(models.EventRatingChanged, models.EventRatingChangedVersion, models.RatingChanged{
+		UserID:    change.UserID,
+		Username:  change.Username,
+		OldRating: change.OldRating,
+		NewRating: change.NewRating,
+		OldRank:   oldRank,
+		NewRank:   newRank,
+		Source:    change.Source,
+		AppliedAt: change.AppliedAt.UTC(),
+	})
+	if err == nil {
+		_, err = s.redisRepo.AppendEvent(ctx, values, s.config.MaxLen)
+	}
+	if err != nil {
+		metrics.EventsPublished.WithLabelValues(models.EventRatingChanged, "failed").Inc()
+		metrics.RedisWriteFailures.WithLabelValues("append_event").Inc()
+		log.Printf("Failed to publish rating change for %s: %v", change.Username, err)
+		return
+	}
+	metrics.EventsPublished.WithLabelValues(models.EventRatingChanged, "published").Inc()
+}
+
+// Replay returns up to limit events after the cursor since, oldest first.
+// since is the ID of the last event the client has seen, an RFC 3339 time
+// to start from, or empty (or "0") for the oldest event still retained.
+func (s *EventService) Replay(ctx context.Context, since string, limit int) (_ *models.EventPage, err error) {
+	ctx, span := tracing.Start(ctx, "EventService.Replay", trace.WithAttributes(
+		attribute.String("events.since", since),
+		attribute.Int("events.limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if limit < 1 || limit > 1000 {
+		return nil, apperrors.Validation("limit must be between 1 and 1000").WithDetail("limit", limit)
+	}
+	if since == "" {
+		since = "0"
+	}
+	start, err := replayStart(since)
+	if err != nil {
+		return nil, err
+	}
+	if s.redisRepo == nil {
+		return nil, apperrors.Unavailable("the event stream needs Redis, which is not configured")
+	}
+	if !s.redisRepo.Available() {
+		return nil, apperrors.Unavailable("Redis is unavailable")
+	}
+
+	msgs, err := s.redisRepo.ReadEvents(ctx, start, int64(limit))
+	if err != nil {
+		return nil, apperrors.Unavailable("failed to read the event stream").Wrap(err)
+	}
+	page := &models.EventPage{Events: make([]models.StreamEvent, 0, len(msgs)), Next: since}
+	for _, msg := range msgs {
+		event, err := events.Decode(msg)
+		if err != nil {
+			log.Printf("Skipping event in replay: %v", err)
+			continue
+		}
+		page.Events = append(page.Events, event)
+	}
+	if len(msgs) > 0 {
+		page.Next = msgs[len(msgs)-1].ID
+	}
+
+	// The cursor is older than anything retained, so events after it may
+	// have been trimmed
+	if start != "-" {
+		oldest, err := s.redisRepo.ReadEvents(ctx, "-", 1)
+		if err != nil {
+			return nil, apperrors.Unavailable("failed to read the event stream").Wrap(err)
+		}
+		page.Truncated = len(oldest) > 0 && events.Compare(oldest[0].ID, start) > 0
+	}
+	return page, nil
+}
+
+// replayStart turns a replay cursor into the first stream ID to read
+func replayStart(since string) (string, error) {
+	if since == "0" {
+		return "-", nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return events.IDAt(t), nil
+	}
+	start, err := events.After(since)
+	if err != nil {
+		return "", apperrors.Validation("since must be an event ID or an RFC 3339 time").WithDetail("since", since)
+	}
+	return start, nil
+}
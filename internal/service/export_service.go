@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+)
+
+// Export formats
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// ExportContentType returns the MIME type for an export format
+func ExportContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatJSONL:
+		return "application/x-ndjson", nil
+	default:
+		return "", apperrors.Validation("unknown export format %q (want %s or %s)", format, FormatCSV, FormatJSONL)
+	}
+}
+
+// exportBatchSize is how many users Export reads per query
+const exportBatchSize = 5000
+
+// ExportService streams the full leaderboard with ranks
+type ExportService struct {
+	userRepo *repository.UserRepository
+}
+
+func NewExportService(userRepo *repository.UserRepository) *ExportService {
+	return &ExportService{userRepo: userRepo}
+}
+
+// Export writes every user in leaderboard order as CSV (with a header row) or
+// JSON lines, and returns the number of users written. Ranks are tie-aware
+// and computed while streaming, so memory use does not grow with the board.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, format string) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "ExportService.Export")
+	defer func() { tracing.End(span, err) }()
+
+	buf := bufio.NewWriter(w)
+	var write func(models.LeaderboardEntry) error
+	flush := buf.Flush
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(buf)
+		if err := cw.Write([]string{"rank", "username", "rating"}); err != nil {
+			return 0, err
+		}
+		write = func(e models.LeaderboardEntry) error {
+			return cw.Write([]string{strconv.Itoa(e.Rank), e.Username, strconv.Itoa(e.Rating)})
+		}
+		flush = func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return buf.Flush()
+		}
+	case FormatJSONL:
+		enc := json.NewEncoder(buf)
+		write = func(e models.LeaderboardEntry) error { return enc.Encode(e) }
+	default:
+		return 0, apperrors.Validation("unknown export format %q (want %s or %s)", format, FormatCSV, FormatJSONL)
+	}
+
+	written, rank := 0, 0
+	var after *models.User
+	for {
+		users, err := s.userRepo.ListUsersByRating(ctx, after, exportBatchSize)
+		if err != nil {
+			return written, err
+		}
+		for _, user := range users {
+			// Tied users share the rank of the first user with that rating
+			if after == nil || user.Rating != after.Rating {
+				rank = written + 1
+			}
+			entry := models.LeaderboardEntry{Rank: rank, Username: user.Username, Rating: user.Rating}
+			if err := write(entry); err != nil {
+				return written, err
+			}
+			written++
+			u := user
+			after = &u
+		}
+		if len(users) < exportBatchSize {
+			break
+		}
+	}
+
+	if err := flush(); err != nil {
+		return written, err
+	}
+	audit.SetChange(ctx, nil, map[string]interface{}{"format": format, "users_exported": written})
+	return written, nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+)
+
+// Readiness statuses
+const (
+	StatusReady    = "ready"
+	StatusDegraded = "degraded"
+	StatusNotReady = "not_ready"
+)
+
+// HealthService runs the readiness checks. Postgres is required; Redis only
+// degrades the instance unless configured as required, since every read can
+// fall back to Postgres.
+type HealthService struct {
+	healthRepo    *repository.HealthRepository
+	userRepo      *repository.UserRepository
+	redisRepo     *repository.RedisRepository
+	syncService   *SyncService
+	updateService *UpdateService
+	config        config.HealthConfig
+
+	// The drift check counts every user, so its result is reused for config.DriftInterval
+	driftMu      sync.Mutex
+	driftResult  models.HealthCheck
+	driftChecked time.Time
+}
+
+func NewHealthService(
+	healthRepo *repository.HealthRepository,
+	userRepo *repository.UserRepository,
+	redisRepo *repository.RedisRepository,
+	syncService *SyncService,
+	updateService *UpdateService,
+	cfg config.HealthConfig,
+) *HealthService {
+	return &HealthService{
+		healthRepo:    healthRepo,
+		userRepo:      userRepo,
+		redisRepo:     redisRepo,
+		syncService:   syncService,
+		updateService: updateService,
+		config:        cfg,
+	}
+}
+
+// Readiness runs every check concurrently and summarizes them
+func (s *HealthService) Readiness(ctx context.Context) *models.ReadinessResponse {
+	checks := map[string]func(context.Context) models.HealthCheck{
+		"database":     s.checkDatabase,
+		"redis":        s.checkRedis,
+		"redis_sync":   s.checkRedisSync,
+		"update_queue": s.checkUpdateQueue,
+		"redis_drift":  s.checkRedisDrift,
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]models.HealthCheck, len(checks))
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) models.HealthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.config.CheckTimeout)
+			defer cancel()
+			result := check(checkCtx)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	status := StatusReady
+	for _, result := range results {
+		switch result.Status {
+		case models.CheckFail:
+			status = StatusNotReady
+		case models.CheckWarn:
+			if status == StatusReady {
+				status = StatusDegraded
+			}
+		}
+	}
+
+	return &models.ReadinessResponse{
+		Status:    status,
+		CheckedAt: time.Now().UTC(),
+		Checks:    results,
+	}
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) models.HealthCheck {
+	start := time.Now()
+	err := s.healthRepo.Ping(ctx)
+	result := models.HealthCheck{Status: models.CheckPass, LatencyMs: sinceMs(start)}
+	if err != nil {
+		result.Status = models.CheckFail
+		result.Message = fmt.Sprintf("ping failed: %v", err)
+	}
+
+	if stats, err := s.healthRepo.Stats(); err == nil {
+		result.Details = map[string]interface{}{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+			"max_open":         stats.MaxOpenConnections,
+			"wait_count":       stats.WaitCount,
+		}
+	}
+	return result
+}
+
+func (s *HealthService) checkRedis(_ context.Context) models.HealthCheck {
+	if s.redisRepo == nil {
+		return models.HealthCheck{Status: models.CheckPass, Message: "Redis is not configured"}
+	}
+
+	// The circuit reflects the background probe, so this doesn't add Redis traffic
+	state, since := s.redisRepo.CircuitState()
+	result := models.HealthCheck{
+		Status:  models.CheckPass,
+		Details: map[string]interface{}{"circuit": state.String(), "since": since.UTC()},
+	}
+	if !s.redisRepo.Available() {
+		result.Status = s.redisDownStatus()
+		result.Message = "circuit open; serving from Postgres"
+	}
+	return result
+}
+
+func (s *HealthService) checkRedisSync(_ context.Context) models.HealthCheck {
+	if s.redisRepo == nil {
+		return models.HealthCheck{Status: models.CheckPass, Message: "Redis is not configured"}
+	}
+
+	status := s.syncService.Status()
+	result := models.HealthCheck{Status: models.CheckPass, Details: map[string]interface{}{"syncing": status.Syncing}}
+	if !status.LastSuccess.IsZero() {
+		result.Details["last_success"] = status.LastSuccess.UTC()
+	}
+	if status.LastError != nil {
+		result.Details["last_error"] = status.LastError.Error()
+	}
+
+	switch {
+	case status.LastSuccess.IsZero() && status.Syncing:
+		// Don't take traffic until the first sync lands; it only takes a moment
+		result.Status = models.CheckFail
+		result.Message = "initial Redis sync in progress"
+	case status.LastSuccess.IsZero():
+		result.Status = s.redisDownStatus()
+		result.Message = "Redis has not been synced yet"
+	}
+	return result
+}
+
+func (s *HealthService) checkUpdateQueue(_ context.Context) models.HealthCheck {
+	depth, capacity := s.updateService.QueueStats()
+	result := models.HealthCheck{
+		Status:  models.CheckPass,
+		Details: map[string]interface{}{"depth": depth, "capacity": capacity},
+	}
+	if float64(depth) >= float64(capacity)*s.config.QueueSaturation {
+		result.Status = models.CheckFail
+		result.Message = fmt.Sprintf("update queue is %d/%d full", depth, capacity)
+	}
+	return result
+}
+
+func (s *HealthService) checkRedisDrift(ctx context.Context) models.HealthCheck {
+	if !s.redisRepo.Available() {
+		return models.HealthCheck{Status: models.CheckPass, Message: "skipped: Redis is not in use"}
+	}
+
+	s.driftMu.Lock()
+	defer s.driftMu.Unlock()
+	if !s.driftChecked.IsZero() && time.Since(s.driftChecked) < s.config.DriftInterval {
+		return s.driftResult
+	}
+
+	start := time.Now()
+	dbCount, err := s.userRepo.GetTotalUsers(ctx)
+	if err != nil {
+		return models.HealthCheck{Status: models.CheckWarn, Message: fmt.Sprintf("failed to count users in Postgres: %v", err)}
+	}
+	redisCount, err := s.redisRepo.GetTotalUsers(ctx)
+	if err != nil {
+		return models.HealthCheck{Status: models.CheckWarn, Message: fmt.Sprintf("failed to count users in Redis: %v", err)}
+	}
+
+	drift := redisCount - dbCount
+	if drift < 0 {
+		drift = -drift
+	}
+	result := models.HealthCheck{
+		Status:    models.CheckPass,
+		LatencyMs: sinceMs(start),
+		Details: map[string]interface{}{
+			"database": dbCount,
+			"redis":    redisCount,
+			"drift":    drift,
+		},
+	}
+	switch {
+	case s.config.MaxDrift > 0 && drift > s.config.MaxDrift:
+		result.Status = models.CheckFail
+		result.Message = fmt.Sprintf("Redis and Postgres differ by %d users", drift)
+	case drift > 0:
+		result.Status = models.CheckWarn
+		result.Message = fmt.Sprintf("Redis and Postgres differ by %d users", drift)
+	}
+
+	s.driftResult = result
+	s.driftChecked = time.Now()
+	return result
+}
+
+// redisDownStatus is how an unusable Redis affects readiness
+func (s *HealthService) redisDownStatus() string {
+	if s.config.RequireRedis {
+		return models.CheckFail
+	}
+	return models.CheckWarn
+}
+
+func sinceMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+)
+
+// maxReportedImportErrors caps the row errors kept in an ImportReport
+const maxReportedImportErrors = 500
+
+// maxImportLineBytes is the longest JSON line Import accepts
+const maxImportLineBytes = 64 * 1024
+
+// ImportService bulk loads users and ratings from CSV or JSON lines
+type ImportService struct {
+	userRepo    *repository.UserRepository
+	syncService *SyncService
+}
+
+func NewImportService(userRepo *repository.UserRepository, syncService *SyncService) *ImportService {
+	return &ImportService{userRepo: userRepo, syncService: syncService}
+}
+
+// Import reads users from r and upserts them by username. CSV needs a header
+// with username and rating columns; other columns (such as rank in an export)
+// are ignored. JSON lines are objects with username and rating. Invalid rows
+// are skipped and reported; the valid ones are committed together. Redis is
+// rebuilt afterwards if anything changed.
+func (s *ImportService) Import(ctx context.Context, r io.Reader, format string) (_ *models.ImportReport, err error) {
+	ctx, span := tracing.Start(ctx, "ImportService.Import")
+	defer func() { tracing.End(span, err) }()
+
+	var reader importReader
+	switch format {
+	case FormatCSV:
+		if reader, err = newCSVImportReader(r); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		reader = newJSONLImportReader(r)
+	default:
+		return nil, apperrors.Validation("unknown import format %q (want %s or %s)", format, FormatCSV, FormatJSONL)
+	}
+
+	report := &models.ImportReport{Errors: []models.ImportRowError{}}
+	fail := func(line int, username, msg string) {
+		report.Failed++
+		if len(report.Errors) < maxReportedImportErrors {
+			report.Errors = append(report.Errors, models.ImportRowError{Line: line, Username: username, Error: msg})
+		}
+	}
+
+	next := func() (repository.ImportRow, bool, error) {
+		for {
+			raw, ok, err := reader.next()
+			if err != nil || !ok {
+				return repository.ImportRow{}, false, err
+			}
+			report.Rows++
+			if raw.err != nil {
+				fail(raw.line, raw.username, raw.err.Error())
+				continue
+			}
+			row, err := validateImportRow(raw)
+			if err != nil {
+				fail(raw.line, raw.username, err.Error())
+				continue
+			}
+			return row, true, nil
+		}
+	}
+
+	result, err := s.userRepo.ImportUsers(ctx, next)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	for _, dup := range result.Duplicates {
+		fail(dup.Line, dup.Username, "duplicate username; the first occurrence was imported")
+	}
+	for _, row := range result.Deleted {
+		fail(row.Line, row.Username, "username belongs to a deleted user")
+	}
+	sort.Slice(report.Errors, func(i, j int) bool { return report.Errors[i].Line < report.Errors[j].Line })
+	report.Inserted, report.Updated, report.Unchanged = result.Inserted, result.Updated, result.Unchanged
+	log.Printf("Imported %d rows: %d inserted, %d updated, %d unchanged, %d failed",
+		report.Rows, report.Inserted, report.Updated, report.Unchanged, report.Failed)
+
+	report.RedisSynced = true
+	if report.Inserted+report.Updated > 0 {
+		if _, err := s.syncService.SyncRedis(ctx); err != nil {
+			log.Printf("Redis not synced after import: %v", err)
+			report.RedisSynced = false
+			report.RedisError = apperrors.As(err).Message
+		}
+	}
+	audit.SetChange(ctx, nil, report)
+	return report, nil
+}
+
+// rawImportRow is a row as read from the file, before validation
+type rawImportRow struct {
+	line     int
+	username string
+	rating   string
+	// err is set when the row could not be parsed at all
+	err error
+}
+
+// importReader yields rows until ok is false. An error aborts the import.
+type importReader interface {
+	next() (row rawImportRow, ok bool, err error)
+}
+
+func validateImportRow(raw rawImportRow) (repository.ImportRow, error) {
+	username := strings.TrimSpace(raw.username)
+	if username == "" {
+		return repository.ImportRow{}, errors.New("username is required")
+	}
+	if err := validateUsername(username); err != nil {
+		return repository.ImportRow{}, err
+	}
+	if strings.TrimSpace(raw.rating) == "" {
+		return repository.ImportRow{}, errors.New("rating is required")
+	}
+	rating, err := strconv.Atoi(strings.TrimSpace(raw.rating))
+	if err != nil {
+		return repository.ImportRow{}, fmt.Errorf("rating %q is not an integer", raw.rating)
+	}
+	if rating < models.MinRating || rating > models.MaxRating {
+		return repository.ImportRow{}, fmt.Errorf("rating must be between %d and %d", models.MinRating, models.MaxRating)
+	}
+	return repository.ImportRow{Line: raw.line, Username: username, Rating: rating}, nil
+}
+
+type csvImportReader struct {
+	r              *csv.Reader
+	usernameColumn int
+	ratingColumn   int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, apperrors.Validation("import file is empty")
+	}
+	if err != nil {
+		return nil, apperrors.Validation("invalid CSV header: %v", err)
+	}
+
+	c := &csvImportReader{r: cr, usernameColumn: -1, ratingColumn: -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "username":
+			c.usernameColumn = i
+		case "rating":
+			c.ratingColumn = i
+		}
+	}
+	if c.usernameColumn < 0 || c.ratingColumn < 0 {
+		return nil, apperrors.Validation("CSV header must include username and rating columns")
+	}
+	return c, nil
+}
+
+func (c *csvImportReader) next() (rawImportRow, bool, error) {
+	record, err := c.r.Read()
+	if err == io.EOF {
+		return rawImportRow{}, false, nil
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return rawImportRow{line: parseErr.Line, err: errors.New(parseErr.Err.Error())}, true, nil
+	}
+	if err != nil {
+		return rawImportRow{}, false, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	if len(record) <= max(c.usernameColumn, c.ratingColumn) {
+		return rawImportRow{line: line, err: errors.New("missing username or rating column")}, true, nil
+	}
+	return rawImportRow{line: line, username: record[c.usernameColumn], rating: record[c.ratingColumn]}, true, nil
+}
+
+type jsonlImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLImportReader(r io.Reader) *jsonlImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxImportLineBytes)
+	return &jsonlImportReader{scanner: scanner}
+}
+
+func (j *jsonlImportReader) next() (rawImportRow, bool, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := strings.TrimSpace(j.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var obj struct {
+			Username string          `json:"username"`
+			Rating   json.RawMessage `json:"rating"`
+		}
+		if err := json.Unmarshal([]byte(text), &obj); err != nil {
+			return rawImportRow{line: j.line, err: fmt.Errorf("invalid JSON: %v", err)}, true, nil
+		}
+		return rawImportRow{line: j.line, username: obj.Username, rating: string(obj.Rating)}, true, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return rawImportRow{}, false, apperrors.Validation("line %d is longer than %d bytes", j.line+1, maxImportLineBytes)
+		}
+		return rawImportRow{}, false, err
+	}
+	return rawImportRow{}, false, nil
+}
@@ -2,57 +2,147 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"log"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/metrics"
 	"matiks/leaderboard/internal/models"
 	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LeaderboardService struct {
 	userRepo  *repository.UserRepository
 	redisRepo *repository.RedisRepository
+	pageCache *cache.PageCache
 }
 
 type leaderboardService interface {
-	GetLeaderboard(page, limit int) (*models.LeaderboardResponse, error)
+	GetLeaderboard(ctx context.Context, page, limit int) (*models.LeaderboardResponse, error)
+	GetLeaderboardPage(ctx context.Context, page, limit int) (*cache.Page, error)
 	calculateRanks(users []models.User) []models.LeaderboardEntry
 }
 
+// GetLeaderboardPage returns a serialized leaderboard page, served from the
+// page cache for hot pages.
+func (s *LeaderboardService) GetLeaderboardPage(ctx context.Context, page, limit int) (_ *cache.Page, err error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardService.GetLeaderboardPage")
+	defer func() { tracing.End(span, err) }()
+
+	cacheable := s.pageCache.Cacheable(page)
+	if cacheable {
+		if cached, ok := s.pageCache.Get(page, limit); ok {
+			span.SetAttributes(attribute.Bool("cache.hit", true))
+			return cached, nil
+		}
+	}
+
+	// Read the version before the data so an invalidation racing with this
+	// read prevents the (possibly stale) page from being stored
+	var version uint64
+	if cacheable {
+		version = s.pageCache.Version()
+	}
+
+	response, err := s.GetLeaderboard(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &cache.Page{
+		Body:  body,
+		ETag:  cache.ETag(body),
+		Empty: len(response.Entries) == 0,
+	}
+	if !result.Empty {
+		// Entries are sorted by rating descending
+		result.MaxScore = response.Entries[0].Rating
+		result.MinScore = response.Entries[len(response.Entries)-1].Rating
+	}
+
+	if cacheable {
+		s.pageCache.Set(page, limit, result, version)
+	}
+	return result, nil
+}
+
 // GetLeaderboard implements [leaderboardService].
-func (s *LeaderboardService) GetLeaderboard(page, limit int) (*models.LeaderboardResponse, error) {
+func (s *LeaderboardService) GetLeaderboard(ctx context.Context, page, limit int) (_ *models.LeaderboardResponse, err error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardService.GetLeaderboard", trace.WithAttributes(
+		attribute.Int("leaderboard.page", page),
+		attribute.Int("leaderboard.limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	if s.redisRepo == nil {
-		return s.getLeaderboardFromDB(page, limit)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", "redis_disabled").Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
+	}
+	if !s.redisRepo.Available() {
+		// Don't log per request during an outage; the circuit transition is logged once
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", "circuit_open").Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
 	}
-
-	ctx := context.Background()
 
 	offset := int64((page - 1) * limit)
 	limit64 := int64(limit)
 
-	totalRedis, err := s.redisRepo.GetTotalUsers(ctx)
+	// Redis only gets part of the request budget so a slow Redis can still fall back to the DB
+	redisCtx, cancel := redisBudget(ctx)
+	defer cancel()
+
+	totalRedis, err := s.redisRepo.GetTotalUsers(redisCtx)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		log.Printf("Redis check failed: %v, falling back to DB", err)
-		return s.getLeaderboardFromDB(page, limit)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", redisFallbackReason(err)).Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
 	}
 
 	if totalRedis == 0 {
 		log.Println("Redis is empty, falling back to DB")
-		return s.getLeaderboardFromDB(page, limit)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", "redis_empty").Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
 	}
 
-	redisEntries, err := s.redisRepo.GetLeaderboard(ctx, offset, limit64)
+	redisEntries, err := s.redisRepo.GetLeaderboard(redisCtx, offset, limit64)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		log.Printf("Redis GetLeaderboard failed: %v, falling back to DB", err)
-		return s.getLeaderboardFromDB(page, limit)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", redisFallbackReason(err)).Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
 	}
 
 	if len(redisEntries) == 0 {
 		log.Println("Redis returned empty results, falling back to DB")
-		return s.getLeaderboardFromDB(page, limit)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", "empty_page").Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
 	}
 
-	entries := s.convertRedisEntriesToLeaderboardEntries(redisEntries, offset)
+	entries, err := s.convertRedisEntriesToLeaderboardEntries(ctx, redisCtx, redisEntries, offset)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Printf("Redis profile lookup failed: %v, falling back to DB", err)
+		metrics.RedisFallbacks.WithLabelValues("get_leaderboard", redisFallbackReason(err)).Inc()
+		return s.getLeaderboardFromDB(ctx, page, limit)
+	}
+	span.SetAttributes(attribute.String("leaderboard.source", "redis"))
+	metrics.RedisHits.WithLabelValues("get_leaderboard").Inc()
 	log.Printf("Redis leaderboard hit - page %d, limit %d, total %d", page, limit, totalRedis)
 
 	return &models.LeaderboardResponse{
@@ -63,7 +153,8 @@ func (s *LeaderboardService) GetLeaderboard(page, limit int) (*models.Leaderboar
 	}, nil
 }
 
-func (l *LeaderboardService) getLeaderboardFromDB(page, limit int) (*models.LeaderboardResponse, error) {
+func (l *LeaderboardService) getLeaderboardFromDB(ctx context.Context, page, limit int) (*models.LeaderboardResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("leaderboard.source", "db"))
 
 	if page < 1 {
 		page = 1
@@ -73,7 +164,7 @@ func (l *LeaderboardService) getLeaderboardFromDB(page, limit int) (*models.Lead
 		limit = 50
 	}
 
-	users, err := l.userRepo.GetLeaderboard(page, limit)
+	users, err := l.userRepo.GetLeaderboard(ctx, page, limit)
 
 	if err != nil {
 		return nil, err
@@ -81,7 +172,7 @@ func (l *LeaderboardService) getLeaderboardFromDB(page, limit int) (*models.Lead
 
 	entries := l.calculateRanks(users)
 
-	total, err := l.userRepo.GetTotalUsers()
+	total, err := l.userRepo.GetTotalUsers(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -113,14 +204,38 @@ func (l *LeaderboardService) calculateRanks(users []models.User) []models.Leader
 	return entries
 }
 
-func NewLeaderboardService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository) leaderboardService {
+// NewLeaderboardService creates a LeaderboardService. pageCache may be nil to disable page caching.
+func NewLeaderboardService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, pageCache *cache.PageCache) leaderboardService {
 	return &LeaderboardService{
 		userRepo:  userRepo,
 		redisRepo: redisRepo,
+		pageCache: pageCache,
 	}
 }
 
-func (s *LeaderboardService) convertRedisEntriesToLeaderboardEntries(redisEntries []redis.Z, offset int64) []models.LeaderboardEntry {
+// convertRedisEntriesToLeaderboardEntries ranks a page of Redis members and
+// fills in usernames from the profile hash with one HMGET. Profiles missing
+// from the hash are read from the DB and cached again.
+func (s *LeaderboardService) convertRedisEntriesToLeaderboardEntries(ctx, redisCtx context.Context, redisEntries []redis.Z, offset int64) ([]models.LeaderboardEntry, error) {
+	ids := make([]int, len(redisEntries))
+	for i, entry := range redisEntries {
+		id, err := repository.MemberID(entry.Member)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+
+	profiles, err := s.redisRepo.GetProfiles(redisCtx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) < len(ids) {
+		if err := s.hydrateMissingProfiles(ctx, redisCtx, ids, profiles); err != nil {
+			return nil, err
+		}
+	}
+
 	entries := make([]models.LeaderboardEntry, 0, len(redisEntries))
 
 	// ZRevRangeWithScores returns in descending order (highest score first)
@@ -133,12 +248,41 @@ func (s *LeaderboardService) convertRedisEntriesToLeaderboardEntries(redisEntrie
 			currentRank = int(offset) + i + 1
 		}
 
+		profile, ok := profiles[ids[i]]
+		if !ok {
+			// In Redis but no longer in the DB; the next sync drops it
+			continue
+		}
 		entries = append(entries, models.LeaderboardEntry{
 			Rank:     currentRank,
-			Username: entry.Member.(string),
+			Username: profile.Username,
 			Rating:   int(entry.Score),
 		})
 	}
 
-	return entries
+	return entries, nil
+}
+
+// hydrateMissingProfiles adds the profiles missing from profiles from the DB
+// and caches them in Redis
+func (s *LeaderboardService) hydrateMissingProfiles(ctx, redisCtx context.Context, ids []int, profiles map[int]repository.Profile) error {
+	missing := make([]int, 0, len(ids)-len(profiles))
+	for _, id := range ids {
+		if _, ok := profiles[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	users, err := s.userRepo.GetUsersByIDs(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		profiles[user.ID] = repository.Profile{Username: user.Username}
+	}
+	metrics.RedisFallbacks.WithLabelValues("get_profiles", "cache_miss").Add(float64(len(missing)))
+	if err := s.redisRepo.SaveProfiles(redisCtx, users); err != nil {
+		log.Printf("Failed to cache %d profiles: %v", len(users), err)
+	}
+	return nil
 }
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxReasonLength caps the reason a moderator gives for a change
+	maxReasonLength = 500
+	// maxBulkRollbackUsers caps the users in one bulk rollback
+	maxBulkRollbackUsers = 1000
+)
+
+// ModerationService lets moderators correct ratings. Every change is written
+// through UpdateService.Apply, so it reaches Redis, peaks, the page cache and
+// rating_history like any other, tagged with the moderator's reason.
+//
+// A rollback subtracts the net of a user's changes since a time, or of those
+// from one match, leaving later unrelated changes in place. Rollback rows are
+// recorded with the same match, so they are part of that net and rolling back
+// twice changes nothing.
+type ModerationService struct {
+	userRepo      *repository.UserRepository
+	updateService *UpdateService
+}
+
+func NewModerationService(userRepo *repository.UserRepository, updateService *UpdateService) *ModerationService {
+	return &ModerationService{userRepo: userRepo, updateService: updateService}
+}
+
+// ratingAudit is the part of a user recorded in the audit log for a rating
+// correction
+type ratingAudit struct {
+	Rating int    `json:"rating"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SetRating sets a user's rating to an absolute value
+func (s *ModerationService) SetRating(ctx context.Context, username string, rating int, reason string) (_ *models.RatingCorrection, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationService.SetRating", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.Int("user.new_rating", rating),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateRating(rating); err != nil {
+		return nil, err
+	}
+	return s.correct(ctx, username, reason, func(models.User) (int, error) {
+		return rating, nil
+	})
+}
+
+// AdjustRating adds delta, which may be negative, to a user's rating
+func (s *ModerationService) AdjustRating(ctx context.Context, username string, delta int, reason string) (_ *models.RatingCorrection, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationService.AdjustRating", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.Int("rating.delta", delta),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if delta == 0 {
+		return nil, apperrors.Validation("delta must not be zero")
+	}
+	return s.correct(ctx, username, reason, func(user models.User) (int, error) {
+		return user.Rating + delta, validateRating(user.Rating + delta)
+	})
+}
+
+// correct applies a moderator's change picked by rate from the locked user
+func (s *ModerationService) correct(ctx context.Context, username, reason string, rate func(user models.User) (int, error)) (*models.RatingCorrection, error) {
+	reason, err := validateReason(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var rateErr error
+	write := repository.RatingWrite{Source: models.RatingSourceAdmin, Reason: &reason}
+	change, err := s.updateService.Apply(ctx, username, write, func(user models.User) (int, bool) {
+		rating, err := rate(user)
+		if err != nil {
+			rateErr = err
+			return 0, false
+		}
+		return rating, rating != user.Rating
+	})
+	if err != nil {
+		return nil, userLookupError(err, username)
+	}
+	if rateErr != nil {
+		return nil, rateErr
+	}
+	if change == nil {
+		user, err := s.userRepo.GetUserByUsername(ctx, username)
+		if err != nil {
+			return nil, userLookupError(err, username)
+		}
+		return &models.RatingCorrection{Username: user.Username, OldRating: user.Rating, NewRating: user.Rating, Reason: reason}, nil
+	}
+
+	audit.SetChange(ctx, ratingAudit{Rating: change.OldRating}, ratingAudit{Rating: change.NewRating, Reason: reason})
+	return &models.RatingCorrection{
+		Username:  change.Username,
+		OldRating: change.OldRating,
+		NewRating: change.NewRating,
+		Reason:    reason,
+	}, nil
+}
+
+// Rollback reverts a user's rating changes made at or after since, only those
+// from matchID when it is set
+func (s *ModerationService) Rollback(ctx context.Context, username string, since time.Time, matchID *int, reason string) (_ *models.RatingRollback, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationService.Rollback", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	reason, err = validateRollback(since, reason)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.rollback(ctx, username, since, matchID, reason)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status == models.RollbackApplied {
+		audit.SetChange(ctx, ratingAudit{Rating: result.OldRating}, ratingAudit{Rating: result.NewRating, Reason: reason})
+	}
+	return result, nil
+}
+
+// BulkRollback rolls back each of usernames as Rollback does. A user that
+// fails does not stop the rest; the report gives each user's outcome.
+func (s *ModerationService) BulkRollback(ctx context.Context, usernames []string, since time.Time, matchID *int, reason string) (_ *models.BulkRollbackReport, err error) {
+	ctx, span := tracing.Start(ctx, "ModerationService.BulkRollback", trace.WithAttributes(
+		attribute.Int("rollback.users", len(usernames)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if len(usernames) == 0 {
+		return nil, apperrors.Validation("usernames is required")
+	}
+	if len(usernames) > maxBulkRollbackUsers {
+		return nil, apperrors.Validation("at most %d usernames can be rolled back at once", maxBulkRollbackUsers).
+			WithDetail("usernames", len(usernames))
+	}
+	reason, err = validateRollback(since, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BulkRollbackReport{Since: since, MatchID: matchID, Results: make([]models.RatingRollback, 0, len(usernames))}
+	seen := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := s.rollback(ctx, username, since, matchID, reason)
+		switch {
+		case apperrors.Is(err, apperrors.KindNotFound):
+			result = &models.RatingRollback{Username: username, Status: models.RollbackNotFound}
+		case err != nil:
+			result = &models.RatingRollback{Username: username, Status: models.RollbackFailed, Error: err.Error()}
+		}
+
+		switch result.Status {
+		case models.RollbackApplied:
+			report.RolledBack++
+		case models.RollbackUnchanged:
+			report.Unchanged++
+		case models.RollbackNotFound:
+			report.NotFound++
+		default:
+			report.Failed++
+		}
+		report.Results = append(report.Results, *result)
+	}
+
+	audit.SetChange(ctx, nil, map[string]interface{}{
+		"reason":      reason,
+		"rolled_back": report.RolledBack,
+		"unchanged":   report.Unchanged,
+		"not_found":   report.NotFound,
+		"failed":      report.Failed,
+	})
+	return report, nil
+}
+
+// rollback subtracts the net of the matching changes from the user's rating.
+// The net is read before the user is locked, so the write only goes ahead if
+// the rating has not moved since.
+func (s *ModerationService) rollback(ctx context.Context, username string, since time.Time, matchID *int, reason string) (*models.RatingRollback, error) {
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, userLookupError(err, username)
+	}
+	changes, net, err := s.userRepo.NetRatingChangeSince(ctx, user.ID, since, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RatingRollback{
+		Username:  user.Username,
+		Status:    models.RollbackUnchanged,
+		Changes:   changes,
+		OldRating: user.Rating,
+		NewRating: user.Rating,
+	}
+	if net == 0 {
+		return result, nil
+	}
+	target := user.Rating - net
+	if err := validateRating(target); err != nil {
+		return nil, err
+	}
+
+	moved := false
+	write := repository.RatingWrite{Source: models.RatingSourceRollback, MatchID: matchID, Reason: &reason}
+	change, err := s.updateService.Apply(ctx, username, write, func(locked models.User) (int, bool) {
+		if locked.ID != user.ID || locked.Rating != user.Rating {
+			moved = true
+			return 0, false
+		}
+		return target, true
+	})
+	if err != nil {
+		return nil, userLookupError(err, username)
+	}
+	if moved || change == nil {
+		return nil, apperrors.Conflict("rating of %q changed during the rollback; retry", username).
+			WithDetail("username", username)
+	}
+
+	result.Status = models.RollbackApplied
+	result.NewRating = change.NewRating
+	return result, nil
+}
+
+func validateRating(rating int) error {
+	if rating < models.MinRating || rating > models.MaxRating {
+		return apperrors.Validation("rating must be between %d and %d", models.MinRating, models.MaxRating).
+			WithDetail("rating", rating)
+	}
+	return nil
+}
+
+// validateReason trims the reason a moderator gave and checks it is present
+// and not too long
+func validateReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", apperrors.Validation("reason is required")
+	}
+	if utf8.RuneCountInString(reason) > maxReasonLength {
+		return "", apperrors.Validation("reason must be at most %d characters", maxReasonLength)
+	}
+	return reason, nil
+}
+
+func validateRollback(since time.Time, reason string) (string, error) {
+	if since.IsZero() {
+		return "", apperrors.Validation("since is required")
+	}
+	if since.After(time.Now()) {
+		return "", apperrors.Validation("since must not be in the future").WithDetail("since", since)
+	}
+	return validateReason(reason)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PeakService tracks users' all-time best ranks and serves the hall of fame.
+// Peak ratings are kept by the rating writes themselves; peak ranks are
+// recorded by the update workers when a user climbs, and by a periodic sweep
+// for users who climb because others dropped.
+type PeakService struct {
+	userRepo  *repository.UserRepository
+	redisRepo *repository.RedisRepository
+}
+
+func NewPeakService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository) *PeakService {
+	return &PeakService{userRepo: userRepo, redisRepo: redisRepo}
+}
+
+// Sweep records a new peak rank for every user ranked higher than ever
+// before and returns how many there were
+func (s *PeakService) Sweep(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "PeakService.Sweep")
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	updated, err := s.userRepo.SweepPeakRanks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int64("peaks.updated", updated))
+	log.Printf("Peak rank sweep: %d new peaks in %v", updated, time.Since(start))
+	return updated, nil
+}
+
+// RecordRank checks whether a ranked user's current rating gives them a new
+// peak rank and records it
+func (s *PeakService) RecordRank(ctx context.Context, user models.User) error {
+	if !user.Ranked() {
+		return nil
+	}
+	rank, err := rankForRating(ctx, s.userRepo, s.redisRepo, user.Rating, "record_peak_rank")
+	if err != nil {
+		return err
+	}
+	_, err = s.userRepo.RecordPeakRank(ctx, user.ID, rank)
+	return err
+}
+
+// HallOfFame returns a page of ranked users ordered by all-time peak rating
+func (s *PeakService) HallOfFame(ctx context.Context, page, limit int) (_ *models.HallOfFameResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PeakService.HallOfFame", trace.WithAttributes(
+		attribute.Int("hall_of_fame.page", page),
+		attribute.Int("hall_of_fame.limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+
+	users, err := s.userRepo.GetHallOfFame(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.userRepo.GetTotalUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.HallOfFameEntry, 0, len(users))
+	rank := 0
+	for i, user := range users {
+		switch {
+		case i == 0:
+			// The page may start partway through a tie
+			higher, err := s.userRepo.CountUsersWithHigherPeakRating(ctx, user.PeakRating)
+			if err != nil {
+				return nil, err
+			}
+			rank = int(higher) + 1
+		case users[i-1].PeakRating != user.PeakRating:
+			rank = (page-1)*limit + i + 1
+		}
+		entries = append(entries, models.HallOfFameEntry{
+			Rank:         rank,
+			Username:     user.Username,
+			PeakRating:   user.PeakRating,
+			PeakRatingAt: user.PeakRatingAt,
+			PeakRank:     user.PeakRank,
+			PeakRankAt:   user.PeakRankAt,
+			Rating:       user.Rating,
+		})
+	}
+
+	return &models.HallOfFameResponse{
+		Entries: entries,
+		Page:    page,
+		Limit:   limit,
+		Total:   int(total),
+	}, nil
+}
+
+// rankForRating returns the tie-aware rank of a rating, from Redis when it is
+// available and from the DB otherwise. operation labels the Redis metrics.
+func rankForRating(ctx context.Context, userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, rating int, operation string) (int, error) {
+	if redisRepo != nil {
+		redisCtx, cancel := redisBudget(ctx)
+		defer cancel()
+		higher, err := redisRepo.CountUsersWithHigherRating(redisCtx, rating)
+		if err == nil {
+			metrics.RedisHits.WithLabelValues(operation).Inc()
+			return int(higher) + 1, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		metrics.RedisFallbacks.WithLabelValues(operation, redisFallbackReason(err)).Inc()
+	} else {
+		metrics.RedisFallbacks.WithLabelValues(operation, redisFallbackReason(errRedisDisabled)).Inc()
+	}
+
+	higher, err := userRepo.CountUsersWithHigherRating(ctx, rating)
+	if err != nil {
+		return 0, err
+	}
+	return int(higher) + 1, nil
+}
+
+// totalRankedUsers counts the users on the leaderboard, from Redis when it is
+// available and from the DB otherwise
+func totalRankedUsers(ctx context.Context, userRepo *repository.UserRepository, redisRepo *repository.RedisRepository) (int64, error) {
+	if redisRepo != nil {
+		redisCtx, cancel := redisBudget(ctx)
+		defer cancel()
+		if total, err := redisRepo.GetTotalUsers(redisCtx); err == nil {
+			return total, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+	}
+	return userRepo.GetTotalUsers(ctx)
+}
+
+// changeRanks returns a user's rank before and after an applied change, or
+// nil for a rank the user did not hold: before the change when it brought
+// them back onto the board, after it when they are off the board. The old
+// rank is derived from ratings after the write, so it matches the board as it
+// stood just before the change.
+func changeRanks(ctx context.Context, userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, change RatingChange, operation string) (oldRank, newRank *int, err error) {
+	if !change.Ranked {
+		return nil, nil, nil
+	}
+	rank, err := rankForRating(ctx, userRepo, redisRepo, change.NewRating, operation)
+	if err != nil {
+		return nil, nil, err
+	}
+	newRank = &rank
+	if change.Rejoined {
+		return nil, newRank, nil
+	}
+
+	old, err := rankForRating(ctx, userRepo, redisRepo, change.OldRating, operation)
+	if err != nil {
+		return nil, nil, err
+	}
+	if change.NewRating > change.OldRating {
+		// The user now counts among those rated above their old rating
+		old--
+	}
+	return &old, newRank, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+)
+
+// mirrorToRedis makes the Redis leaderboard reflect a user change and returns
+// the outcome for the updates metric. Ranked users have their score and
+// profile written; users who leave the board are removed. A failed write
+// marks Redis stale, so it is re-synced before it is read again.
+func mirrorToRedis(ctx context.Context, redisRepo *repository.RedisRepository, before, after models.User) string {
+	if redisRepo == nil {
+		return "success"
+	}
+
+	var err error
+	switch {
+	case after.Ranked():
+		err = redisRepo.SaveLeaderboardEntry(ctx, after)
+	case before.ID != 0 && before.Ranked():
+		err = redisRepo.RemoveLeaderboardEntry(ctx, after.ID)
+	default:
+		return "success"
+	}
+
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, repository.ErrRedisUnavailable):
+		// Redis is re-synced from the DB, and skipped writes replayed, when its circuit closes again
+		return "redis_skipped"
+	default:
+		redisRepo.MarkStale(err)
+		return "redis_error"
+	}
+}
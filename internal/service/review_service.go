@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// ReviewService is the moderators' queue of rating anomalies. Approving a
+// held update applies it through UpdateService.Apply, which skips the
+// anti-cheat rules; rejecting it discards it.
+type ReviewService struct {
+	userRepo      *repository.UserRepository
+	antiCheatRepo *repository.AntiCheatRepository
+	updateService *UpdateService
+}
+
+func NewReviewService(userRepo *repository.UserRepository, antiCheatRepo *repository.AntiCheatRepository, updateService *UpdateService) *ReviewService {
+	return &ReviewService{userRepo: userRepo, antiCheatRepo: antiCheatRepo, updateService: updateService}
+}
+
+// List returns a page of anomalies with the given status, oldest first. An
+// empty status lists every anomaly.
+func (s *ReviewService) List(ctx context.Context, status string, page, limit int) (_ *models.AnomalyListResponse, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewService.List", trace.WithAttributes(
+		attribute.String("anomaly.status", status),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	switch status {
+	case "", models.AnomalyPending, models.AnomalyApproved, models.AnomalyRejected, models.AnomalyFlagged:
+	default:
+		return nil, apperrors.Validation("status must be pending, approved, rejected or flagged").WithDetail("status", status)
+	}
+	if page < 1 {
+		return nil, apperrors.Validation("page must be at least 1").WithDetail("page", page)
+	}
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+
+	anomalies, err := s.antiCheatRepo.List(ctx, status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.antiCheatRepo.Count(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AnomalyListResponse{Anomalies: anomalies, Page: page, Limit: limit, Total: total}, nil
+}
+
+// Approve applies a held update. The held rating replaces the user's
+// current one, as the update would have when it was submitted.
+func (s *ReviewService) Approve(ctx context.Context, id int, note string) (_ *models.RatingAnomaly, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewService.Approve", trace.WithAttributes(
+		attribute.Int("anomaly.id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	anomaly, err := s.resolve(ctx, id, models.AnomalyApproved, note)
+	if err != nil {
+		return nil, err
+	}
+
+	// The anomaly is claimed, so a second moderator cannot apply it too. If
+	// the update cannot be applied it goes back in the queue.
+	if err := s.apply(ctx, anomaly); err != nil {
+		if reopenErr := s.antiCheatRepo.Reopen(ctx, id); reopenErr != nil {
+			log.Printf("Failed to reopen anomaly %d after a failed approval: %v", id, reopenErr)
+		}
+		return nil, err
+	}
+
+	audit.SetChange(ctx, map[string]string{"status": models.AnomalyPending}, anomaly)
+	return anomaly, nil
+}
+
+// Reject discards a held update
+func (s *ReviewService) Reject(ctx context.Context, id int, note string) (_ *models.RatingAnomaly, err error) {
+	ctx, span := tracing.Start(ctx, "ReviewService.Reject", trace.WithAttributes(
+		attribute.Int("anomaly.id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	anomaly, err := s.resolve(ctx, id, models.AnomalyRejected, note)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.SetChange(ctx, map[string]string{"status": models.AnomalyPending}, anomaly)
+	return anomaly, nil
+}
+
+// resolve claims a pending anomaly for the current actor
+func (s *ReviewService) resolve(ctx context.Context, id int, status, note string) (*models.RatingAnomaly, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxReasonLength {
+		return nil, apperrors.Validation("note must be at most %d characters", maxReasonLength)
+	}
+	var notePtr *string
+	if note != "" {
+		notePtr = &note
+	}
+
+	anomaly, err := s.antiCheatRepo.Resolve(ctx, id, status, audit.ActorFrom(ctx).ID, notePtr)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperrors.NotFound("anomaly %d not found", id).WithDetail("id", id)
+	case errors.Is(err, repository.ErrAlreadyReviewed):
+		return nil, apperrors.Conflict("anomaly %d is not pending review", id).WithDetail("id", id)
+	case err != nil:
+		return nil, err
+	}
+	return anomaly, nil
+}
+
+// apply writes the held rating to the user the anomaly was raised for, under
+// their current username. It returns a conflict, leaving the user untouched,
+// if they were banned or their rating changed since the update was held, so
+// the moderator decides again with the current state.
+func (s *ReviewService) apply(ctx context.Context, anomaly *models.RatingAnomaly) error {
+	users, err := s.userRepo.GetUsersByIDs(ctx, []int{anomaly.UserID})
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return apperrors.NotFound("user %q no longer exists", anomaly.Username).WithDetail("username", anomaly.Username)
+	}
+
+	username := users[0].Username
+	var conflict *apperrors.Error
+	_, err = s.updateService.Apply(ctx, username, repository.RatingWrite{Source: anomaly.Source}, func(user models.User) (int, bool) {
+		switch {
+		case user.ID != anomaly.UserID:
+			conflict = apperrors.Conflict("user %q was renamed during the review", username)
+		case user.BannedAt != nil:
+			conflict = apperrors.Conflict("user %q has been banned since the update was held", username)
+		case user.Rating != anomaly.OldRating:
+			conflict = apperrors.Conflict("rating of %q changed since the update was held", username).
+				WithDetail("held_from", anomaly.OldRating).
+				WithDetail("current_rating", user.Rating)
+		default:
+			return anomaly.NewRating, true
+		}
+		return 0, false
+	})
+	if err != nil {
+		return userLookupError(err, username)
+	}
+	if conflict != nil {
+		return conflict.WithDetail("username", username)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SnapshotService keeps periodic copies of the top of the leaderboard in
+// Postgres for later comparison
+type SnapshotService struct {
+	snapshotRepo *repository.SnapshotRepository
+	config       config.SnapshotConfig
+}
+
+func NewSnapshotService(snapshotRepo *repository.SnapshotRepository, cfg config.SnapshotConfig) *SnapshotService {
+	return &SnapshotService{snapshotRepo: snapshotRepo, config: cfg}
+}
+
+// Take snapshots the top of the leaderboard and removes snapshots past the
+// retention period. It returns the new snapshot and how many old ones were
+// removed.
+func (s *SnapshotService) Take(ctx context.Context) (_ *models.LeaderboardSnapshot, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "SnapshotService.Take")
+	defer func() { tracing.End(span, err) }()
+
+	snapshot, err := s.snapshotRepo.TakeSnapshot(ctx, s.config.Size)
+	if err != nil {
+		return nil, 0, err
+	}
+	cutoff := snapshot.TakenAt.Add(-time.Duration(s.config.RetentionDays) * 24 * time.Hour)
+	removed, err := s.snapshotRepo.DeleteSnapshotsBefore(ctx, cutoff)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	span.SetAttributes(attribute.Int("snapshot.id", snapshot.ID), attribute.Int64("snapshot.removed", removed))
+	log.Printf("Leaderboard snapshot %d taken of %d users; %d expired snapshots removed", snapshot.ID, snapshot.TotalUsers, removed)
+	return snapshot, removed, nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SyncService keeps the Redis leaderboard in step with Postgres: it re-syncs
+// Redis whenever it comes back from an outage and on demand from the admin API.
+type SyncService struct {
+	userRepo    *repository.UserRepository
+	redisRepo   *repository.RedisRepository
+	broadcaster *cache.Broadcaster
+
+	mu     sync.Mutex
+	status SyncStatus
+}
+
+// SyncStatus describes the Redis sync state
+type SyncStatus struct {
+	// Syncing is true while a sync is running
+	Syncing bool
+	// LastSuccess is when Redis was last fully synced; zero until the initial sync completes
+	LastSuccess time.Time
+	// LastError is the error of the most recent sync, if it failed
+	LastError error
+}
+
+func NewSyncService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, broadcaster *cache.Broadcaster) *SyncService {
+	return &SyncService{userRepo: userRepo, redisRepo: redisRepo, broadcaster: broadcaster}
+}
+
+// Start monitors Redis in the background until ctx is canceled. The first
+// successful probe performs the startup sync; later ones re-sync after an outage.
+func (s *SyncService) Start(ctx context.Context, probeInterval time.Duration) {
+	if s.redisRepo == nil {
+		log.Println("Running without Redis - using database only")
+		return
+	}
+	go s.redisRepo.Monitor(ctx, probeInterval, s.resync, s.catchUp)
+}
+
+// SyncRedis rebuilds Redis from Postgres on demand and returns the number of users synced
+func (s *SyncService) SyncRedis(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "SyncService.SyncRedis")
+	defer func() { tracing.End(span, err) }()
+
+	if s.redisRepo == nil {
+		return 0, apperrors.Unavailable("Redis is not configured")
+	}
+	if !s.redisRepo.Available() {
+		return 0, apperrors.Unavailable("Redis is unavailable; it will be re-synced automatically when it recovers")
+	}
+
+	log.Println("Manual Redis sync triggered...")
+	count, err := s.syncAndCatchUp(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, apperrors.Unavailable("failed to sync Redis").Wrap(err)
+	}
+	audit.SetChange(ctx, nil, map[string]interface{}{"users_synced": count})
+	return count, nil
+}
+
+// Reconcile verifies Redis against Postgres and rebuilds it if they differ.
+// It returns what Verify found and whether Redis was rebuilt.
+func (s *SyncService) Reconcile(ctx context.Context) (_ *VerifyReport, resynced bool, err error) {
+	ctx, span := tracing.Start(ctx, "SyncService.Reconcile")
+	defer func() { tracing.End(span, err) }()
+
+	report, err := s.Verify(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if report.InSync() {
+		return report, false, nil
+	}
+
+	log.Printf("Redis drifted from Postgres (%d missing, %d extra, %d mismatched, %d stale profiles), re-syncing",
+		len(report.MissingInRedis), len(report.ExtraInRedis), len(report.Mismatched), len(report.StaleProfiles))
+	if _, err := s.syncAndCatchUp(ctx); err != nil {
+		return report, false, err
+	}
+	return report, true, nil
+}
+
+// resync rebuilds Redis while its circuit is open. Monitor catches up once
+// the circuit has closed, which also covers writes made during the sync.
+func (s *SyncService) resync(ctx context.Context) error {
+	_, err := s.sync(ctx)
+	return err
+}
+
+// syncAndCatchUp rebuilds Redis while its circuit is closed, then re-applies
+// users changed during the sync, whose live writes the rebuild overwrote
+func (s *SyncService) syncAndCatchUp(ctx context.Context) (int, error) {
+	start := time.Now()
+	count, err := s.sync(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.catchUp(ctx, start); err != nil {
+		s.redisRepo.MarkStale(err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (s *SyncService) catchUp(ctx context.Context, since time.Time) error {
+	if err := s.userRepo.CatchUpRedis(ctx, s.redisRepo, since); err != nil {
+		return err
+	}
+	s.broadcaster.InvalidateAll(ctx)
+	return nil
+}
+
+// Status returns the current sync state
+func (s *SyncService) Status() SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *SyncService) sync(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	s.status.Syncing = true
+	s.mu.Unlock()
+
+	count, err := s.userRepo.SyncAllUserToRedis(ctx, s.redisRepo)
+
+	s.mu.Lock()
+	s.status.Syncing = false
+	s.status.LastError = err
+	if err == nil {
+		s.status.LastSuccess = time.Now()
+	}
+	s.mu.Unlock()
+
+	// Pages may have been cached from a stale sorted set
+	s.broadcaster.InvalidateAll(ctx)
+	if err != nil {
+		log.Printf("Failed to sync to Redis: %v", err)
+		return 0, err
+	}
+	log.Printf("Successfully synced %d users to Redis", count)
+	return count, nil
+}
+
+// VerifyReport describes how Redis differs from Postgres
+type VerifyReport struct {
+	DatabaseUsers int64
+	RedisUsers    int64
+	// MissingInRedis are users in Postgres with no Redis entry
+	MissingInRedis []string
+	// ExtraInRedis are Redis members with no ranked user in Postgres
+	ExtraInRedis []string
+	// Mismatched are users whose Redis score differs from their rating
+	Mismatched []RatingMismatch
+	// StaleProfiles are users whose cached profile is missing or out of date
+	StaleProfiles []string
+}
+
+// RatingMismatch is a user whose Redis score disagrees with Postgres
+type RatingMismatch struct {
+	Username    string
+	DBRating    int
+	RedisRating int
+}
+
+// InSync reports whether Redis matches Postgres exactly
+func (r *VerifyReport) InSync() bool {
+	return len(r.MissingInRedis) == 0 && len(r.ExtraInRedis) == 0 && len(r.Mismatched) == 0 && len(r.StaleProfiles) == 0
+}
+
+// verifyBatchSize is how many users Verify reads per query
+const verifyBatchSize = 5000
+
+// Verify compares every user in Postgres with the Redis leaderboard
+func (s *SyncService) Verify(ctx context.Context) (_ *VerifyReport, err error) {
+	ctx, span := tracing.Start(ctx, "SyncService.Verify")
+	defer func() { tracing.End(span, err) }()
+
+	if !s.redisRepo.Available() {
+		return nil, apperrors.Unavailable("Redis is unavailable")
+	}
+
+	redisRatings := make(map[string]int)
+	err = s.redisRepo.ScanLeaderboard(ctx, verifyBatchSize, func(entries []redis.Z) error {
+		for _, entry := range entries {
+			member, _ := entry.Member.(string)
+			redisRatings[member] = int(entry.Score)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{RedisUsers: int64(len(redisRatings))}
+	var after *models.User
+	for {
+		users, err := s.userRepo.ListUsersByRating(ctx, after, verifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		profiles, err := s.redisRepo.GetProfiles(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			report.DatabaseUsers++
+			if profiles[user.ID].Username != user.Username {
+				report.StaleProfiles = append(report.StaleProfiles, user.Username)
+			}
+			member := repository.Member(user.ID)
+			redisRating, ok := redisRatings[member]
+			switch {
+			case !ok:
+				report.MissingInRedis = append(report.MissingInRedis, user.Username)
+			case redisRating != user.Rating:
+				report.Mismatched = append(report.Mismatched, RatingMismatch{
+					Username:    user.Username,
+					DBRating:    user.Rating,
+					RedisRating: redisRating,
+				})
+			}
+			delete(redisRatings, member)
+		}
+		if len(users) < verifyBatchSize {
+			break
+		}
+		after = &users[len(users)-1]
+	}
+
+	for username := range redisRatings {
+		report.ExtraInRedis = append(report.ExtraInRedis, username)
+	}
+	sort.Strings(report.ExtraInRedis)
+	return report, nil
+}
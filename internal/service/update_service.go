@@ -2,35 +2,77 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"math/rand"
+	"matiks/leaderboard/internal/anticheat"
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
 	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UpdateService struct {
 	userRepo   *repository.UserRepository
 	redisRepo  *repository.RedisRepository
+	peaks      *PeakService
+	antiCheat  *AntiCheatService
 	updateChan chan UpdateRequest
 	workers    int
 	wg         sync.WaitGroup
+
+	observersMu sync.RWMutex
+	observers   []UpdateObserver
 }
 
+// RatingChange describes a rating update applied by a worker or Apply
+type RatingChange struct {
+	UserID    int
+	Username  string
+	OldRating int
+	NewRating int
+	// Source is what caused the change, one of the models.RatingSource constants
+	Source    string
+	AppliedAt time.Time
+	// Rejoined is set when the change brought a user hidden for inactivity
+	// back onto the leaderboard
+	Rejoined bool
+	// Ranked is set when the user is on the leaderboard after the change
+	Ranked bool
+}
+
+// UpdateObserver is notified after a rating change has been written to the DB and Redis
+type UpdateObserver func(ctx context.Context, change RatingChange)
+
 type UpdateRequest struct {
 	Username  string
 	NewRating int
+	Source    string
+
+	// SpanContext of the caller that queued the update, linked from the worker span
+	SpanContext trace.SpanContext
 }
 
-func NewUpdateService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository) *UpdateService {
+// NewUpdateService creates an UpdateService and starts its workers. antiCheat
+// screens queued updates and may be nil to apply them unchecked.
+func NewUpdateService(userRepo *repository.UserRepository, redisRepo *repository.RedisRepository, peaks *PeakService, antiCheat *AntiCheatService) *UpdateService {
 	service := &UpdateService{
 		userRepo:   userRepo,
 		redisRepo:  redisRepo,
+		peaks:      peaks,
+		antiCheat:  antiCheat,
 		updateChan: make(chan UpdateRequest, 100), // Buffer for 100 updates
 		workers:    5,                             // Number of concurrent workers
 	}
 
+	metrics.RegisterQueue(func() int { return len(service.updateChan) }, cap(service.updateChan))
+
 	// Start worker goroutines
 	for i := 0; i < service.workers; i++ {
 		service.wg.Add(1)
@@ -43,41 +85,204 @@ func NewUpdateService(userRepo *repository.UserRepository, redisRepo *repository
 // worker processes updates from the channel
 func (s *UpdateService) worker(id int) {
 	defer s.wg.Done()
-	ctx := context.Background()
 
 	for update := range s.updateChan {
-		log.Printf("Worker %d: Updating %s to rating %d", id, update.Username, update.NewRating)
+		s.applyUpdate(context.Background(), id, update)
+	}
+}
 
-		// Update database
-		if err := s.userRepo.UpdateUserRating(ctx, update.Username, update.NewRating); err != nil {
-			log.Printf("Worker %d: Failed to update DB for %s: %v", id, update.Username, err)
-			continue
+// applyUpdate writes a single update to the database and then Redis.
+// Each update gets its own root span, linked to the span that queued it.
+func (s *UpdateService) applyUpdate(ctx context.Context, id int, update UpdateRequest) {
+	var err error
+	ctx, span := tracing.Start(ctx, "UpdateService.applyUpdate",
+		trace.WithNewRoot(),
+		trace.WithLinks(trace.Link{SpanContext: update.SpanContext}),
+		trace.WithAttributes(
+			attribute.Int("worker.id", id),
+			attribute.String("user.username", update.Username),
+			attribute.Int("user.new_rating", update.NewRating),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	log.Printf("Worker %d: Updating %s to rating %d", id, update.Username, update.NewRating)
+
+	// Player updates are screened by anti-cheat. If their history cannot be
+	// loaded the update goes ahead unchecked rather than being lost.
+	var screen *screening
+	if s.antiCheat != nil {
+		var screenErr error
+		if screen, screenErr = s.antiCheat.prepare(ctx, update); screenErr != nil {
+			log.Printf("Worker %d: Anti-cheat skipped for %s: %v", id, update.Username, screenErr)
 		}
+	}
 
-		// Update Redis
-		if s.redisRepo != nil {
-			if err := s.redisRepo.AddToLeaderboard(ctx, update.Username, update.NewRating); err != nil {
-				log.Printf("Worker %d: Failed to update Redis for %s: %v", id, update.Username, err)
+	var result string
+	var verdict anticheat.Verdict
+	var locked models.User
+	var banned bool
+	_, result, err = s.apply(ctx, update.Username, repository.RatingWrite{Source: update.Source}, func(user models.User) (int, bool) {
+		// The user may have been banned since the update was queued
+		if user.BannedAt != nil {
+			banned = true
+			return 0, false
+		}
+		if screen != nil {
+			locked = user
+			verdict = s.antiCheat.screen(screen, user, update.NewRating)
+		}
+		return update.NewRating, verdict.Allows()
+	})
+	if err != nil {
+		log.Printf("Worker %d: Failed to update DB for %s: %v", id, update.Username, err)
+		return
+	}
+	if banned {
+		metrics.UpdatesProcessed.WithLabelValues("banned").Inc()
+		log.Printf("Worker %d: Update for %s not applied: user is banned", id, update.Username)
+		return
+	}
+	if verdict.Action != "" {
+		s.antiCheat.record(ctx, locked, update, verdict)
+		if !verdict.Allows() {
+			label := "rejected"
+			if verdict.Action == models.AntiCheatHold {
+				label = "held"
 			}
+			metrics.UpdatesProcessed.WithLabelValues(label).Inc()
+			log.Printf("Worker %d: Update for %s not applied: anti-cheat %s", id, update.Username, verdict.Action)
+			return
+		}
+	}
+	if result == "redis_error" {
+		log.Printf("Worker %d: Failed to update Redis for %s", id, update.Username)
+	}
+	log.Printf("Worker %d: Successfully updated %s", id, update.Username)
+}
+
+// Apply writes a rating change synchronously through the same path as the
+// workers: the DB and rating history, Redis while the row is locked, peak
+// tracking and the observers. rate picks the new rating from the locked user,
+// or declines, in which case Apply returns a nil change. It is for background
+// jobs and moderators, who must neither queue behind nor be dropped by a full
+// queue.
+func (s *UpdateService) Apply(ctx context.Context, username string, write repository.RatingWrite, rate repository.RateFunc) (*RatingChange, error) {
+	change, _, err := s.apply(ctx, username, write, rate)
+	return change, err
+}
+
+// apply writes one rating change and returns it with its result label
+func (s *UpdateService) apply(ctx context.Context, username string, write repository.RatingWrite, rate repository.RateFunc) (*RatingChange, string, error) {
+	start := time.Now()
+
+	// Update the database, and Redis while the user's row is still locked
+	result := "success"
+	before, after, err := s.userRepo.UpdateUserRating(ctx, username, write, rate,
+		func(before, after models.User) {
+			result = mirrorToRedis(ctx, s.redisRepo, before, after)
+		})
+	if err != nil {
+		metrics.UpdatesProcessed.WithLabelValues("db_error").Inc()
+		return nil, "db_error", err
+	}
+	if after == nil {
+		return nil, "unchanged", nil
+	}
+
+	// Climbing can only improve the user's own rank; gains from others
+	// dropping are picked up by the peak rank sweep
+	if after.Rating > before.Rating {
+		if err := s.peaks.RecordRank(ctx, *after); err != nil {
+			log.Printf("Failed to record peak rank for %s: %v", username, err)
 		}
+	}
+
+	change := &RatingChange{
+		UserID:    after.ID,
+		Username:  username,
+		OldRating: before.Rating,
+		NewRating: after.Rating,
+		Source:    write.Source,
+		AppliedAt: time.Now(),
+		Rejoined:  !before.Ranked() && after.Ranked(),
+		Ranked:    after.Ranked(),
+	}
+	s.notify(ctx, *change)
+
+	metrics.UpdatesProcessed.WithLabelValues(result).Inc()
+	metrics.ObserveSince(metrics.UpdateDuration, start)
+	return change, result, nil
+}
+
+// QueueStats returns the number of queued updates and the queue capacity
+func (s *UpdateService) QueueStats() (depth, capacity int) {
+	return len(s.updateChan), cap(s.updateChan)
+}
 
-		log.Printf("Worker %d: Successfully updated %s", id, update.Username)
+// Observe registers an observer for applied rating changes.
+// Observers run synchronously on the worker, in registration order.
+func (s *UpdateService) Observe(observer UpdateObserver) {
+	s.observersMu.Lock()
+	defer s.observersMu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+func (s *UpdateService) notify(ctx context.Context, change RatingChange) {
+	s.observersMu.RLock()
+	defer s.observersMu.RUnlock()
+	for _, observer := range s.observers {
+		observer(ctx, change)
 	}
 }
 
 // QueueUpdate adds an update to the processing queue (non-blocking)
-func (s *UpdateService) QueueUpdate(username string, newRating int) error {
+func (s *UpdateService) QueueUpdate(ctx context.Context, username string, newRating int, source string) error {
+	update := UpdateRequest{
+		Username:    username,
+		NewRating:   newRating,
+		Source:      source,
+		SpanContext: trace.SpanContextFromContext(ctx),
+	}
+
 	select {
-	case s.updateChan <- UpdateRequest{Username: username, NewRating: newRating}:
+	case s.updateChan <- update:
 		return nil
 	default:
-		return fmt.Errorf("update queue is full")
+		metrics.UpdatesDropped.Inc()
+		return apperrors.QueueFull("update queue is full").WithDetail("capacity", cap(s.updateChan))
 	}
 }
 
+// SubmitRating validates a new rating for an existing user and queues it
+func (s *UpdateService) SubmitRating(ctx context.Context, username string, newRating int) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateService.SubmitRating", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.Int("user.new_rating", newRating),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if newRating < models.MinRating || newRating > models.MaxRating {
+		return apperrors.Validation("rating must be between %d and %d", models.MinRating, models.MaxRating).
+			WithDetail("rating", newRating)
+	}
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return userLookupError(err, username)
+	}
+	if user.BannedAt != nil {
+		return apperrors.Forbidden("user %q is banned", username).WithDetail("username", username)
+	}
+
+	return s.QueueUpdate(ctx, username, newRating, models.RatingSourceSubmission)
+}
+
 // SimulateRandomUpdates updates random users with new ratings
-func (s *UpdateService) SimulateRandomUpdates(count int) error {
-	ctx := context.Background()
+func (s *UpdateService) SimulateRandomUpdates(ctx context.Context, count int) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateService.SimulateRandomUpdates", trace.WithAttributes(
+		attribute.Int("simulate.count", count),
+	))
+	defer func() { tracing.End(span, err) }()
 
 	// Get random users from database
 	users, err := s.userRepo.GetRandomUsers(ctx, count)
@@ -87,15 +292,19 @@ func (s *UpdateService) SimulateRandomUpdates(count int) error {
 
 	rand.Seed(time.Now().UnixNano())
 
+	queued := make(map[string]int, len(users))
 	for _, user := range users {
 		// Generate random rating between 100 and 5000
 		newRating := rand.Intn(4900) + 100
 
 		// Queue update (non-blocking)
-		if err := s.QueueUpdate(user.Username, newRating); err != nil {
+		if err := s.QueueUpdate(ctx, user.Username, newRating, models.RatingSourceSimulation); err != nil {
 			log.Printf("Failed to queue update for %s: %v", user.Username, err)
+			continue
 		}
+		queued[user.Username] = newRating
 	}
+	audit.SetChange(ctx, nil, map[string]interface{}{"queued_ratings": queued})
 
 	log.Printf("Queued %d random updates", len(users))
 	return nil
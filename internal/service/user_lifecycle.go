@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// usernamePattern allows 3-32 letters, digits, underscores, dots and hyphens
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,32}$`)
+
+// validateUsername checks a username chosen at registration, rename or import
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return apperrors.Validation("username must be 3-32 characters of letters, digits, '_', '.' or '-'").
+			WithDetail("username", username)
+	}
+	return nil
+}
+
+// Register creates a user with the given rating, or models.DefaultRating when
+// rating is nil, and adds them to the leaderboard
+func (s *UserService) Register(ctx context.Context, username string, rating *int) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateUsername(username); err != nil {
+		return nil, err
+	}
+	user := &models.User{Username: username, Rating: models.DefaultRating}
+	if rating != nil {
+		if *rating < models.MinRating || *rating > models.MaxRating {
+			return nil, apperrors.Validation("rating must be between %d and %d", models.MinRating, models.MaxRating).
+				WithDetail("rating", *rating)
+		}
+		user.Rating = *rating
+	}
+
+	if err := s.UserRepository.CreateUser(ctx, user, s.mirror(ctx)); err != nil {
+		return nil, userWriteError(err, username)
+	}
+	s.broadcaster.InvalidateAll(ctx)
+	return user, nil
+}
+
+// GetUser returns a user whether or not they are on the leaderboard
+func (s *UserService) GetUser(ctx context.Context, username string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.UserRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, userLookupError(err, username)
+	}
+	return user, nil
+}
+
+// Rename changes a user's username, moving their leaderboard entry with it.
+// Usernames of deleted users stay reserved.
+func (s *UserService) Rename(ctx context.Context, username, newUsername string) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Rename", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.String("user.new_username", newUsername),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateUsername(newUsername); err != nil {
+		return nil, err
+	}
+	return s.modify(ctx, username, func(user *models.User) bool {
+		if user.Username == newUsername {
+			return false
+		}
+		user.Username = newUsername
+		return true
+	})
+}
+
+// Delete soft-deletes a user: they leave the leaderboard and can no longer be
+// looked up, but their rows and history are kept
+func (s *UserService) Delete(ctx context.Context, username string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Delete", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	_, err = s.modify(ctx, username, func(user *models.User) bool {
+		user.DeletedAt.Time, user.DeletedAt.Valid = time.Now(), true
+		return true
+	})
+	return err
+}
+
+// SetBanned bans or unbans a user. Banned users leave the leaderboard and
+// their rating submissions are rejected.
+func (s *UserService) SetBanned(ctx context.Context, username string, banned bool) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetBanned", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.Bool("user.banned", banned),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.modify(ctx, username, func(user *models.User) bool {
+		return setFlag(&user.BannedAt, banned)
+	})
+}
+
+// SetHidden hides or shows a user. Hidden users leave the leaderboard but
+// their rating keeps updating. Showing a user also brings back one hidden for
+// inactivity.
+func (s *UserService) SetHidden(ctx context.Context, username string, hidden bool) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetHidden", trace.WithAttributes(
+		attribute.String("user.username", username),
+		attribute.Bool("user.hidden", hidden),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.modify(ctx, username, func(user *models.User) bool {
+		if hidden && user.HiddenFor(models.HiddenInactive) {
+			// Keep them hidden after they play again
+			reason := models.HiddenAdmin
+			user.HiddenReason = &reason
+			return true
+		}
+		return setHidden(user, hidden, models.HiddenAdmin)
+	})
+}
+
+// modify applies change to a locked user, mirrors it to Redis and drops
+// cached pages it affects
+func (s *UserService) modify(ctx context.Context, username string, change func(user *models.User) bool) (*models.User, error) {
+	var before models.User
+	user, err := s.UserRepository.ModifyUser(ctx, username,
+		func(user *models.User) (bool, error) {
+			before = *user
+			return change(user), nil
+		}, s.mirror(ctx))
+	if err != nil {
+		return nil, userWriteError(err, username)
+	}
+	audit.SetChange(ctx, before.AuditState(), user.AuditState())
+
+	switch {
+	case before.Ranked() != user.Ranked():
+		// Users joined or left the board, which shifts every page's total
+		s.broadcaster.InvalidateAll(ctx)
+	case user.Ranked() && before.Username != user.Username:
+		s.broadcaster.InvalidateRange(ctx, user.Rating, user.Rating)
+	}
+	return user, nil
+}
+
+func (s *UserService) mirror(ctx context.Context) repository.MirrorFunc {
+	return func(before, after models.User) {
+		mirrorToRedis(ctx, s.redisRepo, before, after)
+	}
+}
+
+// setHidden hides the user for reason, or shows them, and reports whether
+// anything changed
+func setHidden(user *models.User, hidden bool, reason string) bool {
+	if !setFlag(&user.HiddenAt, hidden) {
+		return false
+	}
+	if hidden {
+		user.HiddenReason = &reason
+	} else {
+		user.HiddenReason = nil
+	}
+	return true
+}
+
+// setFlag sets or clears a timestamp flag and reports whether it changed
+func setFlag(flag **time.Time, on bool) bool {
+	if (*flag != nil) == on {
+		return false
+	}
+	if on {
+		now := time.Now()
+		*flag = &now
+	} else {
+		*flag = nil
+	}
+	return true
+}
+
+// userWriteError converts a failed user write into a domain error
+func userWriteError(err error, username string) error {
+	if errors.Is(err, repository.ErrUsernameTaken) {
+		return apperrors.Conflict("username is already taken")
+	}
+	return userLookupError(err, username)
+}
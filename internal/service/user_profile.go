@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTrendPoints caps the rating changes listed in a profile trend
+const maxTrendPoints = 50
+
+// GetProfile builds the public profile of a ranked user. The user lookup is
+// required; every other part runs concurrently under its own deadline, and a
+// part that fails is left out and named in Unavailable instead of failing the
+// request.
+func (s *UserService) GetProfile(ctx context.Context, username string) (_ *models.UserProfile, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetProfile", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.rankedUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.UserProfile{
+		Username:       user.Username,
+		Rating:         user.Rating,
+		Tier:           models.TierFor(user.Rating),
+		PeakRating:     user.PeakRating,
+		PeakRatingAt:   user.PeakRatingAt,
+		PeakRank:       user.PeakRank,
+		PeakRankAt:     user.PeakRankAt,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		AccountAgeDays: int(time.Since(user.CreatedAt) / (24 * time.Hour)),
+	}
+
+	// Each component only writes its own fields of profile
+	components := map[string]func(context.Context) error{
+		"rank": func(ctx context.Context) error {
+			return s.profileRank(ctx, user, profile)
+		},
+		"games": func(ctx context.Context) error {
+			games, err := s.UserRepository.CountMatches(ctx, user.ID)
+			if err == nil {
+				profile.GamesPlayed = &games
+			}
+			return err
+		},
+		"trend": func(ctx context.Context) error {
+			return s.profileTrend(ctx, user, profile)
+		},
+		"neighbors": func(ctx context.Context) error {
+			return s.profileNeighbors(ctx, user, profile)
+		},
+		"achievements": func(ctx context.Context) error {
+			achievements, err := s.achievements.ForUser(ctx, user.ID)
+			if err == nil {
+				profile.Achievements = achievements
+			}
+			return err
+		},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, component := range components {
+		wg.Add(1)
+		go func(name string, component func(context.Context) error) {
+			defer wg.Done()
+			componentCtx, cancel := context.WithTimeout(ctx, s.profileConfig.ComponentTimeout)
+			defer cancel()
+			if err := component(componentCtx); err != nil {
+				reason := "error"
+				if errors.Is(err, context.DeadlineExceeded) {
+					reason = "timeout"
+				}
+				log.Printf("Profile of %s: %s unavailable: %v", username, name, err)
+				metrics.ProfileComponentFailures.WithLabelValues(name, reason).Inc()
+				mu.Lock()
+				profile.Unavailable = append(profile.Unavailable, name)
+				mu.Unlock()
+			}
+		}(name, component)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(profile.Unavailable)
+	return profile, nil
+}
+
+// GetAchievements lists the achievements a ranked user holds, oldest first
+func (s *UserService) GetAchievements(ctx context.Context, username string) (_ *models.UserAchievementsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetAchievements", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.rankedUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	achievements, err := s.achievements.ForUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserAchievementsResponse{Username: user.Username, Achievements: achievements}, nil
+}
+
+// profileRank sets the user's rank and percentile
+func (s *UserService) profileRank(ctx context.Context, user *models.User, profile *models.UserProfile) error {
+	rank, err := rankForRating(ctx, &s.UserRepository, s.redisRepo, user.Rating, "user_profile")
+	if err != nil {
+		return err
+	}
+	total, err := totalRankedUsers(ctx, &s.UserRepository, s.redisRepo)
+	if err != nil {
+		return err
+	}
+
+	profile.Rank = &rank
+	if total > 0 {
+		// rank-1 players are rated above the user; everyone else is at or below
+		percentile := math.Round(float64(total-int64(rank-1))/float64(total)*10000) / 100
+		profile.Percentile = &percentile
+	}
+	return nil
+}
+
+// profileTrend sets the user's rating changes over the configured window
+func (s *UserService) profileTrend(ctx context.Context, user *models.User, profile *models.UserProfile) error {
+	since := time.Now().AddDate(0, 0, -s.profileConfig.TrendDays)
+	history, err := s.UserRepository.GetRatingHistorySince(ctx, user.ID, since, maxTrendPoints)
+	if err != nil {
+		return err
+	}
+	start, found, err := s.UserRepository.GetRatingAt(ctx, user.ID, since)
+	if err != nil {
+		return err
+	}
+
+	trend := &models.RatingTrend{Days: s.profileConfig.TrendDays, Points: make([]models.RatingPoint, 0, len(history))}
+	if found {
+		trend.Change = user.Rating - start
+	}
+	for _, entry := range history {
+		trend.Points = append(trend.Points, models.RatingPoint{
+			OldRating: entry.OldRating,
+			NewRating: entry.NewRating,
+			Source:    entry.Source,
+			At:        entry.CreatedAt,
+		})
+	}
+	profile.Trend = trend
+	return nil
+}
+
+// profileNeighbors sets the players ranked directly around the user
+func (s *UserService) profileNeighbors(ctx context.Context, user *models.User, profile *models.UserProfile) error {
+	above, below, err := s.UserRepository.GetNeighbors(ctx, user, s.profileConfig.Neighbors)
+	if err != nil {
+		return err
+	}
+
+	// Neighbors often share ratings, so rank each distinct rating once
+	ranks := make(map[int]int)
+	entries := func(users []models.User) ([]models.LeaderboardEntry, error) {
+		entries := make([]models.LeaderboardEntry, 0, len(users))
+		for _, neighbor := range users {
+			rank, ok := ranks[neighbor.Rating]
+			if !ok {
+				if rank, err = rankForRating(ctx, &s.UserRepository, s.redisRepo, neighbor.Rating, "user_profile"); err != nil {
+					return nil, err
+				}
+				ranks[neighbor.Rating] = rank
+			}
+			entries = append(entries, models.LeaderboardEntry{Rank: rank, Username: neighbor.Username, Rating: neighbor.Rating})
+		}
+		return entries, nil
+	}
+
+	neighbors := &models.ProfileNeighbors{}
+	if neighbors.Above, err = entries(above); err != nil {
+		return err
+	}
+	if neighbors.Below, err = entries(below); err != nil {
+		return err
+	}
+	profile.Neighbors = neighbors
+	return nil
+}
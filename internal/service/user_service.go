@@ -2,58 +2,101 @@ package service
 
 import (
 	"context"
-	"errors"
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/cache"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/metrics"
 	"matiks/leaderboard/internal/models"
 	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UserService struct {
 	UserRepository repository.UserRepository
-	redisRepo      repository.RedisRepository
+	redisRepo      *repository.RedisRepository
+	broadcaster    *cache.Broadcaster
+	profileConfig  config.ProfileConfig
+	achievements   *AchievementService
 }
 
 type userService interface {
-	SearchUsers(query string, page, limit int) (*models.UserSearchResponse, error)
-	GetUserRank(username string) (*models.UserRankResponse, error)
+	SearchUsers(ctx context.Context, query string, page, limit int) (*models.UserSearchResponse, error)
+	GetUserRank(ctx context.Context, username string) (*models.UserRankResponse, error)
+	GetProfile(ctx context.Context, username string) (*models.UserProfile, error)
+	GetAchievements(ctx context.Context, username string) (*models.UserAchievementsResponse, error)
+	Register(ctx context.Context, username string, rating *int) (*models.User, error)
+	GetUser(ctx context.Context, username string) (*models.User, error)
+	Rename(ctx context.Context, username, newUsername string) (*models.User, error)
+	Delete(ctx context.Context, username string) error
+	SetBanned(ctx context.Context, username string, banned bool) (*models.User, error)
+	SetHidden(ctx context.Context, username string, hidden bool) (*models.User, error)
 }
 
-func NewUserService(userRepository *repository.UserRepository, redisRepo *repository.RedisRepository) userService {
-	return &UserService{UserRepository: *userRepository, redisRepo: *redisRepo}
+func NewUserService(userRepository *repository.UserRepository, redisRepo *repository.RedisRepository, broadcaster *cache.Broadcaster, profileConfig config.ProfileConfig, achievements *AchievementService) userService {
+	return &UserService{UserRepository: *userRepository, redisRepo: redisRepo, broadcaster: broadcaster, profileConfig: profileConfig, achievements: achievements}
 
 }
 
-func (s *UserService) SearchUsers(query string, page, limit int) (*models.UserSearchResponse, error) {
+func (s *UserService) SearchUsers(ctx context.Context, query string, page, limit int) (_ *models.UserSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SearchUsers", trace.WithAttributes(
+		attribute.String("search.query", query),
+		attribute.Int("search.page", page),
+		attribute.Int("search.limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	if query == "" {
-		return nil, errors.New("query is required")
+		return nil, apperrors.Validation("query is required")
 	}
 	if page < 1 {
 		page = 1
 	}
 	if limit < 1 || limit > 100 {
-		return nil, errors.New("limit must be between 1 and 100")
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
 	}
 
 	// Get paginated users
-	users, err := s.UserRepository.SearchUsers(query, page, limit)
+	users, err := s.UserRepository.SearchUsers(ctx, query, page, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total count
-	total, err := s.UserRepository.CountSearchUsers(query)
+	total, err := s.UserRepository.CountSearchUsers(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
 	entries := make([]models.LeaderboardEntry, 0)
 
+	// One Redis budget for the whole page; after the first failure the
+	// remaining ranks come straight from the DB instead of waiting again
+	redisCtx, cancel := redisBudget(ctx)
+	defer cancel()
+	useRedis := true
+
 	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		// Calculate rank using Redis (much faster than DB COUNT queries)
-		rank, err := s.calculateUserRankFromRedis(ctx, user.Rating)
-		if err != nil {
+		var rank int
+		if useRedis {
+			rank, err = s.calculateUserRankFromRedis(redisCtx, user.Rating)
+			if err == nil {
+				metrics.RedisHits.WithLabelValues("search_users").Inc()
+			} else {
+				metrics.RedisFallbacks.WithLabelValues("search_users", redisFallbackReason(err)).Inc()
+				useRedis = false
+			}
+		}
+		if !useRedis {
 			// Fallback to DB if Redis fails
-			rank, err = s.calculateUserRank(user.Rating)
+			rank, err = s.calculateUserRank(ctx, user.Rating)
 			if err != nil {
 				return nil, err
 			}
@@ -74,18 +117,24 @@ func (s *UserService) SearchUsers(query string, page, limit int) (*models.UserSe
 	}, nil
 }
 
-func (s *UserService) GetUserRank(username string) (*models.UserRankResponse, error) {
-	ctx := context.Background()
+func (s *UserService) GetUserRank(ctx context.Context, username string) (_ *models.UserRankResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUserRank", trace.WithAttributes(
+		attribute.String("user.username", username),
+	))
+	defer func() { tracing.End(span, err) }()
 
-	user, err := s.UserRepository.GetUserByUsername(username)
+	user, err := s.rankedUser(ctx, username)
 	if err != nil {
 		return nil, err
 	}
 
 	// Try Redis first for rank calculation
 	// Count users with higher rating (tie-aware ranking)
-	rank, err := s.calculateUserRankFromRedis(ctx, user.Rating)
+	redisCtx, cancel := redisBudget(ctx)
+	defer cancel()
+	rank, err := s.calculateUserRankFromRedis(redisCtx, user.Rating)
 	if err == nil {
+		metrics.RedisHits.WithLabelValues("get_user_rank").Inc()
 		return &models.UserRankResponse{
 			Username: user.Username,
 			Rating:   user.Rating,
@@ -93,10 +142,30 @@ func (s *UserService) GetUserRank(username string) (*models.UserRankResponse, er
 		}, nil
 	}
 
-	return s.getUserRankFromDB(username)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	metrics.RedisFallbacks.WithLabelValues("get_user_rank", redisFallbackReason(err)).Inc()
+	return s.getUserRankFromDB(ctx, username)
+}
+
+// rankedUser looks up a user on the leaderboard; hidden and banned users are
+// reported as not found
+func (s *UserService) rankedUser(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.UserRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, userLookupError(err, username)
+	}
+	if !user.Ranked() {
+		return nil, apperrors.NotFound("user %q not found", username).WithDetail("username", username)
+	}
+	return user, nil
 }
 
 func (s *UserService) calculateUserRankFromRedis(ctx context.Context, rating int) (int, error) {
+	if s.redisRepo == nil {
+		return 0, errRedisDisabled
+	}
 	// Count users with rating > user's rating
 	// This gives us tie-aware ranking
 	count, err := s.redisRepo.CountUsersWithHigherRating(ctx, rating)
@@ -106,23 +175,23 @@ func (s *UserService) calculateUserRankFromRedis(ctx context.Context, rating int
 	return int(count) + 1, nil
 }
 
-func (s *UserService) getUserRankFromDB(username string) (*models.UserRankResponse, error) {
+func (s *UserService) getUserRankFromDB(ctx context.Context, username string) (*models.UserRankResponse, error) {
 	if username == "" {
-		return nil, errors.New("username is required")
+		return nil, apperrors.Validation("username is required")
 	}
-	user, err := s.UserRepository.GetUserByUsername(username)
+	user, err := s.rankedUser(ctx, username)
 	if err != nil {
 		return nil, err
 	}
-	rank, err := s.calculateUserRank(user.Rating)
+	rank, err := s.calculateUserRank(ctx, user.Rating)
 	if err != nil {
 		return nil, err
 	}
 	return &models.UserRankResponse{Username: user.Username, Rating: user.Rating, Rank: rank}, nil
 }
 
-func (s *UserService) calculateUserRank(rating int) (int, error) {
-	count, err := s.UserRepository.CountUsersWithHigherRating(rating)
+func (s *UserService) calculateUserRank(ctx context.Context, rating int) (int, error) {
+	count, err := s.UserRepository.CountUsersWithHigherRating(ctx, rating)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/apperrors"
+	"matiks/leaderboard/internal/audit"
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+	"matiks/leaderboard/internal/tracing"
+	"matiks/leaderboard/internal/webhook"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookCacheTTL is how long the list of webhooks is reused by the
+	// update workers; other instances see a new or deleted webhook after it
+	webhookCacheTTL = 10 * time.Second
+	// maxWebhookURLLength caps a webhook's URL
+	maxWebhookURLLength = 2048
+)
+
+// WebhookService manages webhooks and turns applied rating changes into
+// milestone events for them. Events are queued as deliveries in Postgres and
+// sent by the dispatcher.
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	userRepo    *repository.UserRepository
+	redisRepo   *repository.RedisRepository
+	dispatcher  *webhook.Dispatcher
+	config      config.WebhookConfig
+
+	cacheMu  sync.Mutex
+	cached   []models.Webhook
+	cachedAt time.Time
+}
+
+func NewWebhookService(
+	webhookRepo *repository.WebhookRepository,
+	userRepo *repository.UserRepository,
+	redisRepo *repository.RedisRepository,
+	dispatcher *webhook.Dispatcher,
+	cfg config.WebhookConfig,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		redisRepo:   redisRepo,
+		dispatcher:  dispatcher,
+		config:      cfg,
+	}
+}
+
+// Create registers an endpoint for the given event types. The signing
+// secret is only returned here.
+func (s *WebhookService) Create(ctx context.Context, rawURL string, eventTypes []string, description string) (_ *models.CreatedWebhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	if len(eventTypes) == 0 {
+		return nil, apperrors.Validation("at least one event type is required").
+			WithDetail("allowed", models.WebhookEventTypes)
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, apperrors.Validation("unknown event type %q", eventType).
+				WithDetail("allowed", models.WebhookEventTypes)
+		}
+	}
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := models.Webhook{URL: rawURL, EventTypes: slices.Compact(slices.Sorted(slices.Values(eventTypes))), Secret: secret}
+	if description = strings.TrimSpace(description); description != "" {
+		hook.Description = &description
+	}
+	if err := s.webhookRepo.Create(ctx, &hook); err != nil {
+		return nil, err
+	}
+	s.invalidateCache()
+	audit.SetTarget(ctx, "webhook", strconv.Itoa(hook.ID))
+	audit.SetChange(ctx, nil, hook)
+
+	return &models.CreatedWebhook{Webhook: hook, Secret: secret}, nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	if len(rawURL) > maxWebhookURLLength {
+		return apperrors.Validation("url must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperrors.Validation("url must be an absolute http or https URL").WithDetail("url", rawURL)
+	}
+	return nil
+}
+
+// List returns every webhook without its secret
+func (s *WebhookService) List(ctx context.Context) (_ []models.Webhook, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.List")
+	defer func() { tracing.End(span, err) }()
+
+	return s.webhookRepo.List(ctx)
+}
+
+// Delete removes a webhook and its delivery log; pending deliveries are
+// dropped
+func (s *WebhookService) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Delete", trace.WithAttributes(
+		attribute.Int("webhook.id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	hook, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		return webhookLookupError(err, id)
+	}
+	s.invalidateCache()
+	audit.SetChange(ctx, hook, nil)
+	return nil
+}
+
+// Deliveries returns up to limit of a webhook's latest deliveries, newest
+// first, only those with status when it is set
+func (s *WebhookService) Deliveries(ctx context.Context, id int, status string, limit int) (_ []models.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Deliveries", trace.WithAttributes(
+		attribute.Int("webhook.id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	switch status {
+	case "", models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed:
+	default:
+		return nil, apperrors.Validation("status must be pending, succeeded or failed").WithDetail("status", status)
+	}
+	if limit < 1 || limit > 100 {
+		return nil, apperrors.Validation("limit must be between 1 and 100").WithDetail("limit", limit)
+	}
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, id, status, limit)
+}
+
+// Test queues a ping event for one webhook, whatever it subscribes to
+func (s *WebhookService) Test(ctx context.Context, id int) (_ *models.WebhookDelivery, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Test", trace.WithAttributes(
+		attribute.Int("webhook.id", id),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	hook, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	event := newWebhookEvent(models.EventPing, map[string]int{"webhook_id": hook.ID})
+	deliveries, err := s.publish(ctx, []models.WebhookEvent{event}, []models.Webhook{*hook})
+	if err != nil {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+// Prune removes finished deliveries older than the retention period and
+// returns how many were removed
+func (s *WebhookService) Prune(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "WebhookService.Prune")
+	defer func() { tracing.End(span, err) }()
+
+	cutoff := time.Now().AddDate(0, 0, -s.config.RetentionDays)
+	return s.webhookRepo.DeleteDeliveriesBefore(ctx, cutoff)
+}
+
+// OnRatingChange is an UpdateObserver that queues milestone events for a
+// user who climbed: entering a top N, reaching #1 and reaching a higher tier.
+// Ranks are worked out just after the write, so updates landing at the same
+// moment can make them approximate. Failures are logged; the rating change
+// itself has already been applied.
+func (s *WebhookService) OnRatingChange(ctx context.Context, change RatingChange) {
+	if !change.Ranked || change.NewRating <= change.OldRating {
+		return
+	}
+	webhooks, err := s.webhooks(ctx)
+	if err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", change.Username, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	events, err := s.milestones(ctx, change, webhooks)
+	if err != nil {
+		log.Printf("Failed to work out milestones for %s: %v", change.Username, err)
+		return
+	}
+	if _, err := s.publish(ctx, events, webhooks); err != nil {
+		log.Printf("Failed to queue webhook events for %s: %v", change.Username, err)
+	}
+}
+
+// milestones returns the events a climb produced. Ranks are only looked up
+// when some webhook wants a rank event.
+func (s *WebhookService) milestones(ctx context.Context, change RatingChange, webhooks []models.Webhook) ([]models.WebhookEvent, error) {
+	data := models.MilestoneData{Username: change.Username, OldRating: change.OldRating, NewRating: change.NewRating}
+	var events []models.WebhookEvent
+
+	if subscribed(webhooks, models.EventTopEntered) || subscribed(webhooks, models.EventNewLeader) {
+		oldRank, newRank, err := changeRanks(ctx, s.userRepo, s.redisRepo, change, "webhook_milestones")
+		if err != nil {
+			return nil, err
+		}
+		data.OldRank, data.NewRank = oldRank, *newRank
+
+		if data.NewRank == 1 && (data.OldRank == nil || *data.OldRank != 1) {
+			events = append(events, newWebhookEvent(models.EventNewLeader, data))
+		}
+		// Only the tightest boundary crossed is announced
+		for _, top := range s.config.TopRanks {
+			if data.NewRank <= top && (data.OldRank == nil || *data.OldRank > top) {
+				topData := data
+				topData.Top = top
+				events = append(events, newWebhookEvent(models.EventTopEntered, topData))
+				break
+			}
+		}
+	}
+
+	if tier := models.TierFor(change.NewRating); tier != models.TierFor(change.OldRating) {
+		tierData := data
+		tierData.Tier = tier
+		events = append(events, newWebhookEvent(models.EventTierReached, tierData))
+	}
+	return events, nil
+}
+
+// publish queues each event for the webhooks subscribed to it, or for every
+// given webhook in the case of a ping, and wakes the dispatcher
+func (s *WebhookService) publish(ctx context.Context, events []models.WebhookEvent, webhooks []models.Webhook) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	now := time.Now()
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range webhooks {
+			if event.Type != models.EventPing && !hook.Subscribes(event.Type) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     hook.ID,
+				EventID:       event.ID,
+				EventType:     event.Type,
+				Payload:       payload,
+				Status:        models.DeliveryPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	if err := s.webhookRepo.Enqueue(ctx, deliveries); err != nil {
+		return nil, err
+	}
+	s.dispatcher.Notify()
+	return deliveries, nil
+}
+
+func newWebhookEvent(eventType string, data interface{}) models.WebhookEvent {
+	return models.WebhookEvent{ID: webhook.NewEventID(), Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+func subscribed(webhooks []models.Webhook, eventType string) bool {
+	return slices.ContainsFunc(webhooks, func(hook models.Webhook) bool { return hook.Subscribes(eventType) })
+}
+
+// webhooks returns every webhook, cached for webhookCacheTTL
+func (s *WebhookService) webhooks(ctx context.Context) ([]models.Webhook, error) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	if !s.cachedAt.IsZero() && time.Since(s.cachedAt) < webhookCacheTTL {
+		return s.cached, nil
+	}
+	webhooks, err := s.webhookRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.cached, s.cachedAt = webhooks, time.Now()
+	return webhooks, nil
+}
+
+func (s *WebhookService) invalidateCache() {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cached, s.cachedAt = nil, time.Time{}
+}
+
+func (s *WebhookService) get(ctx context.Context, id int) (*models.Webhook, error) {
+	hook, err := s.webhookRepo.Get(ctx, id)
+	if err != nil {
+		return nil, webhookLookupError(err, id)
+	}
+	return hook, nil
+}
+
+func webhookLookupError(err error, id int) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound("webhook %d not found", id).WithDetail("id", id)
+	}
+	return err
+}
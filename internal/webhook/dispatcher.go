@@ -0,0 +1,213 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/metrics"
+	"matiks/leaderboard/internal/models"
+	"matiks/leaderboard/internal/repository"
+)
+
+const (
+	// batchSize is how many deliveries are claimed, and sent concurrently,
+	// at a time
+	batchSize = 20
+	// leaseMargin is added to the attempt timeout when claiming deliveries,
+	// so a slow attempt finishes before another instance may claim it
+	leaseMargin = 30 * time.Second
+	// maxResponseBytes is how much of a response body is read before the
+	// connection is released
+	maxResponseBytes = 64 * 1024
+)
+
+// Dispatcher sends queued deliveries. Every instance runs one; deliveries are
+// claimed with a lease so each attempt is made by one instance. An instance
+// that dies mid-attempt leaves the delivery to be retried after the lease,
+// so endpoints can see a delivery twice and should dedupe on its ID.
+type Dispatcher struct {
+	repo   deliveryStore
+	client *http.Client
+	config config.WebhookConfig
+	wake   chan struct{}
+}
+
+// deliveryStore is the part of repository.WebhookRepository the dispatcher
+// uses, so attempts can be exercised without a database
+type deliveryStore interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	GetByIDs(ctx context.Context, ids []int) (map[int]models.Webhook, error)
+	SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// NewDispatcher creates a Dispatcher; Start begins sending
+func NewDispatcher(repo *repository.WebhookRepository, cfg config.WebhookConfig) *Dispatcher {
+	return newDispatcher(repo, cfg)
+}
+
+func newDispatcher(repo deliveryStore, cfg config.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		repo: repo,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// A redirected POST would be resent as a GET; report it instead
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: cfg,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start sends due deliveries until ctx is done, checking every
+// PollInterval and whenever Notify is called
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			d.dispatchDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Notify wakes the dispatcher after new deliveries were queued
+func (d *Dispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchDue sends claimed batches until no delivery is due
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := d.repo.ClaimDue(ctx, batchSize, d.config.Timeout+leaseMargin)
+		if err != nil {
+			log.Printf("Failed to claim webhook deliveries: %v", err)
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		ids := make([]int, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.WebhookID)
+		}
+		webhooks, err := d.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			log.Printf("Failed to load webhooks for delivery: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			webhook, ok := webhooks[deliveries[i].WebhookID]
+			if !ok {
+				// Deleted since; its deliveries went with it
+				continue
+			}
+			wg.Add(1)
+			go func(delivery *models.WebhookDelivery) {
+				defer wg.Done()
+				d.attempt(ctx, webhook, delivery)
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < batchSize {
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry
+// with backoff until MaxAttempts is reached
+func (d *Dispatcher) attempt(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) {
+	start := time.Now()
+	statusCode, err := d.send(ctx, webhook, delivery)
+	metrics.ObserveSince(metrics.WebhookDeliveryDuration, start)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+	result := "succeeded"
+	switch {
+	case err == nil:
+		delivery.Status, delivery.DeliveredAt, delivery.LastError = models.DeliverySucceeded, &now, nil
+	case delivery.Attempts >= d.config.MaxAttempts:
+		msg := err.Error()
+		delivery.Status, delivery.LastError = models.DeliveryFailed, &msg
+		result = "failed"
+		log.Printf("Webhook delivery %d to webhook %d failed after %d attempts: %v", delivery.ID, webhook.ID, delivery.Attempts, err)
+	default:
+		msg := err.Error()
+		delivery.LastError = &msg
+		delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts, d.config.BackoffBase, d.config.BackoffMax))
+		result = "retry"
+	}
+	metrics.WebhookDeliveries.WithLabelValues(delivery.EventType, result).Inc()
+
+	// Record the outcome even during shutdown, or the delivery is sent again
+	if err := d.repo.SaveAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send posts the payload, returning the response status if there was one.
+// Any status other than 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "matiks-leaderboard-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.ID))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff returns the wait before retrying after the given number of failed
+// attempts: base doubled for each attempt after the first, capped at limit,
+// with the upper half jittered so retries from one outage spread out
+func Backoff(attempts int, base, limit time.Duration) time.Duration {
+	d := limit
+	if shift := max(attempts-1, 0); shift < 32 && base<<shift > 0 && base<<shift < limit {
+		d = base << shift
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
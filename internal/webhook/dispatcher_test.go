@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"matiks/leaderboard/internal/config"
+	"matiks/leaderboard/internal/models"
+)
+
+// fakeStore records saved attempts in memory
+type fakeStore struct {
+	mu    sync.Mutex
+	saved []models.WebhookDelivery
+}
+
+func (s *fakeStore) ClaimDue(context.Context, int, time.Duration) ([]models.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) GetByIDs(context.Context, []int) (map[int]models.Webhook, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) SaveAttempt(_ context.Context, delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = append(s.saved, *delivery)
+	return nil
+}
+
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		Timeout:     2 * time.Second,
+		MaxAttempts: 3,
+		BackoffBase: 10 * time.Second,
+		BackoffMax:  time.Hour,
+	}
+}
+
+func testDelivery() *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:        7,
+		WebhookID: 1,
+		EventID:   "evt_test",
+		EventType: "rank.entered_top",
+		Payload:   []byte(`{"event":"rank.entered_top"}`),
+		Status:    models.DeliveryPending,
+	}
+}
+
+// attemptAgainst runs one attempt of delivery against handler and returns
+// what the dispatcher saved
+func attemptAgainst(t *testing.T, handler http.HandlerFunc, delivery *models.WebhookDelivery) models.WebhookDelivery {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	store := &fakeStore{}
+	d := newDispatcher(store, testConfig())
+	d.attempt(context.Background(), models.Webhook{ID: 1, URL: server.URL, Secret: "whsec_test"}, delivery)
+
+	if len(store.saved) != 1 {
+		t.Fatalf("saved %d attempts, want 1", len(store.saved))
+	}
+	return store.saved[0]
+}
+
+func TestAttemptSignsRequest(t *testing.T) {
+	delivery := testDelivery()
+	var header, event, id string
+	var body []byte
+	saved := attemptAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		header, event, id = r.Header.Get(SignatureHeader), r.Header.Get(EventHeader), r.Header.Get(DeliveryHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}, delivery)
+
+	if err := Verify("whsec_test", header, body, time.Minute); err != nil {
+		t.Errorf("signature %q does not verify: %v", header, err)
+	}
+	if err := Verify("whsec_other", header, body, time.Minute); err == nil {
+		t.Error("signature verified with the wrong secret")
+	}
+	if string(body) != string(delivery.Payload) {
+		t.Errorf("body = %s, want %s", body, delivery.Payload)
+	}
+	if event != delivery.EventType || id != strconv.Itoa(delivery.ID) {
+		t.Errorf("event and delivery headers = %q, %q", event, id)
+	}
+	if saved.Status != models.DeliverySucceeded || saved.DeliveredAt == nil || saved.Attempts != 1 {
+		t.Errorf("saved status %s, delivered at %v, attempts %d; want a single successful attempt", saved.Status, saved.DeliveredAt, saved.Attempts)
+	}
+}
+
+func TestAttemptRetriesServerErrors(t *testing.T) {
+	before := time.Now()
+	saved := attemptAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, testDelivery())
+
+	if saved.Status != models.DeliveryPending || saved.Attempts != 1 {
+		t.Fatalf("status %s after %d attempts, want pending after 1", saved.Status, saved.Attempts)
+	}
+	if saved.LastStatusCode == nil || *saved.LastStatusCode != http.StatusServiceUnavailable {
+		t.Errorf("last status code = %v, want 503", saved.LastStatusCode)
+	}
+	if saved.LastError == nil {
+		t.Error("last error not recorded")
+	}
+	// The first retry waits between half and all of BackoffBase
+	base := testConfig().BackoffBase
+	if earliest := before.Add(base / 2); saved.NextAttemptAt.Before(earliest) {
+		t.Errorf("next attempt at %s, want no earlier than %s", saved.NextAttemptAt, earliest)
+	}
+	if latest := time.Now().Add(base); saved.NextAttemptAt.After(latest) {
+		t.Errorf("next attempt at %s, want no later than %s", saved.NextAttemptAt, latest)
+	}
+}
+
+func TestAttemptFailsAfterMaxAttempts(t *testing.T) {
+	delivery := testDelivery()
+	delivery.Attempts = testConfig().MaxAttempts - 1
+	saved := attemptAgainst(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}, delivery)
+
+	if saved.Status != models.DeliveryFailed {
+		t.Errorf("status %s after %d attempts, want failed", saved.Status, saved.Attempts)
+	}
+	if saved.Attempts != testConfig().MaxAttempts {
+		t.Errorf("attempts = %d, want %d", saved.Attempts, testConfig().MaxAttempts)
+	}
+	if saved.DeliveredAt != nil {
+		t.Error("failed delivery marked delivered")
+	}
+}
+
+func TestAttemptDoesNotFollowRedirects(t *testing.T) {
+	var followed bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	})
+	mux.HandleFunc("/elsewhere", func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := &fakeStore{}
+	d := newDispatcher(store, testConfig())
+	d.attempt(context.Background(), models.Webhook{ID: 1, URL: server.URL + "/hook", Secret: "whsec_test"}, testDelivery())
+
+	if followed {
+		t.Fatal("redirect was followed")
+	}
+	saved := store.saved[0]
+	if saved.Status != models.DeliveryPending || saved.LastStatusCode == nil || *saved.LastStatusCode != http.StatusFound {
+		t.Errorf("status %s with code %v, want a pending retry after a 302", saved.Status, saved.LastStatusCode)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		base     time.Duration
+		limit    time.Duration
+		want     time.Duration
+	}{
+		{"first retry", 1, 10 * time.Second, time.Hour, 10 * time.Second},
+		{"no attempts yet", 0, 10 * time.Second, time.Hour, 10 * time.Second},
+		{"doubles", 2, 10 * time.Second, time.Hour, 20 * time.Second},
+		{"doubles again", 5, 10 * time.Second, time.Hour, 160 * time.Second},
+		{"last below cap", 9, 10 * time.Second, time.Hour, 2560 * time.Second},
+		{"capped", 10, 10 * time.Second, time.Hour, time.Hour},
+		{"shift overflow", 64, 10 * time.Second, time.Hour, time.Hour},
+		{"base above cap", 1, 2 * time.Hour, time.Hour, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Jitter keeps the wait within the upper half of the step
+			for i := 0; i < 100; i++ {
+				got := Backoff(tt.attempts, tt.base, tt.limit)
+				if got < tt.want/2 || got > tt.want {
+					t.Fatalf("Backoff(%d, %s, %s) = %s, want %s-%s", tt.attempts, tt.base, tt.limit, got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}